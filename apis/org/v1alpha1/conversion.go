@@ -0,0 +1,298 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	"github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConversionDataAnnotation holds the v1alpha2 spec and status of an object
+// converted to v1alpha1, such that the v1alpha2 fields without a v1alpha1
+// equivalent are restored when the object is converted back.
+const ConversionDataAnnotation = "org.nddr.yndd.io/conversion-data"
+
+// ConvertTo converts this Organization to the Hub version (v1alpha2).
+func (x *Organization) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1alpha2.Organization)
+	dst.ObjectMeta = x.ObjectMeta
+
+	if x.Spec.Organization != nil {
+		dst.Spec.Organization = &v1alpha2.OrgOrganization{
+			Description:               x.Spec.Organization.Description,
+			Register:                  x.Spec.Organization.Register,
+			AddressAllocationStrategy: x.Spec.Organization.AddressAllocationStrategy,
+		}
+	}
+	dst.Status.ConditionedStatus = x.Status.ConditionedStatus
+	if x.Status.Organization != nil {
+		dst.Status.Organization = &v1alpha2.NddrOrganization{
+			Register:                  x.Status.Organization.Register,
+			AddressAllocationStrategy: x.Status.Organization.AddressAllocationStrategy,
+			State:                     convertStateTo(x.Status.Organization.State),
+		}
+	}
+
+	restored := &v1alpha2.Organization{}
+	ok, err := getConversionData(dst, restored)
+	if err != nil || !ok {
+		return err
+	}
+	if o := restored.Spec.Organization; o != nil {
+		if dst.Spec.Organization == nil {
+			dst.Spec.Organization = &v1alpha2.OrgOrganization{}
+		}
+		dst.Spec.Organization.AdminState = o.AdminState
+		dst.Spec.Organization.DeletionPolicy = o.DeletionPolicy
+		dst.Spec.Organization.RequiredRegisters = o.RequiredRegisters
+		dst.Spec.Organization.DeploymentRequiredRegisters = o.DeploymentRequiredRegisters
+		dst.Spec.Organization.RegistryCredentials = o.RegistryCredentials
+	}
+	if o := restored.Status.Organization; o != nil && dst.Status.Organization != nil {
+		dst.Status.Organization.BlockingDeployments = o.BlockingDeployments
+		dst.Status.Organization.RequiredRegisters = o.RequiredRegisters
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha2) to this version.
+func (x *Organization) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1alpha2.Organization)
+	x.ObjectMeta = src.ObjectMeta
+
+	if src.Spec.Organization != nil {
+		x.Spec.Organization = &OrgOrganization{
+			Description:               src.Spec.Organization.Description,
+			Register:                  src.Spec.Organization.Register,
+			AddressAllocationStrategy: src.Spec.Organization.AddressAllocationStrategy,
+		}
+	}
+	x.Status.ConditionedStatus = src.Status.ConditionedStatus
+	if src.Status.Organization != nil {
+		x.Status.Organization = &NddrOrganization{
+			Register:                  src.Status.Organization.Register,
+			AddressAllocationStrategy: src.Status.Organization.AddressAllocationStrategy,
+			State:                     convertStateFrom(src.Status.Organization.State),
+		}
+	}
+	return setConversionData(x, &v1alpha2.Organization{Spec: src.Spec, Status: v1alpha2.OrganizationStatus{Organization: src.Status.Organization}})
+}
+
+// ConvertTo converts this Deployment to the Hub version (v1alpha2).
+// The organization reference is restored from the conversion data, or else
+// derived from the dotted object name <organization>.<deployment>.
+func (x *Deployment) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1alpha2.Deployment)
+	dst.ObjectMeta = x.ObjectMeta
+
+	if x.Spec.Deployment != nil {
+		dst.Spec.Deployment = &v1alpha2.OrgDeployment{
+			AdminState:                x.Spec.Deployment.AdminState,
+			Description:               x.Spec.Deployment.Description,
+			Region:                    x.Spec.Deployment.Region,
+			Kind:                      x.Spec.Deployment.Kind,
+			Register:                  x.Spec.Deployment.Register,
+			AddressAllocationStrategy: convertAddressAllocationStrategyTo(x.Spec.Deployment.AddressAllocationStrategy),
+		}
+		if split := strings.SplitN(x.GetName(), ".", 2); len(split) == 2 {
+			dst.Spec.Deployment.OrganizationRef = utils.StringPtr(split[0])
+		}
+	}
+	dst.Status.ConditionedStatus = x.Status.ConditionedStatus
+	if x.Status.Deployment != nil {
+		dst.Status.Deployment = &v1alpha2.NddrOrgDeployment{
+			Register:                  convertRegisterTo(x.Status.Deployment.Register),
+			AddressAllocationStrategy: x.Status.Deployment.AddressAllocationStrategy.addressAllocationStrategy(),
+			State:                     convertStateTo(x.Status.Deployment.State),
+		}
+	}
+
+	restored := &v1alpha2.Deployment{}
+	ok, err := getConversionData(dst, restored)
+	if err != nil || !ok {
+		return err
+	}
+	if d := restored.Spec.Deployment; d != nil && d.OrganizationRef != nil {
+		if dst.Spec.Deployment == nil {
+			dst.Spec.Deployment = &v1alpha2.OrgDeployment{}
+		}
+		dst.Spec.Deployment.OrganizationRef = d.OrganizationRef
+	}
+	if d := restored.Status.Deployment; d != nil && dst.Status.Deployment != nil {
+		// the source of a register is kept as long as the register did not change
+		for _, register := range dst.Status.Deployment.Register {
+			for _, r := range d.Register {
+				if equalString(register.Kind, r.Kind) && equalString(register.Name, r.Name) {
+					register.Source = r.Source
+				}
+			}
+		}
+		dst.Status.Deployment.AddressAllocationStrategySource = d.AddressAllocationStrategySource
+		dst.Status.Deployment.RequiredRegisters = d.RequiredRegisters
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha2) to this version.
+// The organization reference has no equivalent in v1alpha1 and is kept in
+// the conversion data.
+func (x *Deployment) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1alpha2.Deployment)
+	x.ObjectMeta = src.ObjectMeta
+
+	if src.Spec.Deployment != nil {
+		x.Spec.Deployment = &OrgDeployment{
+			AdminState:                src.Spec.Deployment.AdminState,
+			Description:               src.Spec.Deployment.Description,
+			Region:                    src.Spec.Deployment.Region,
+			Kind:                      src.Spec.Deployment.Kind,
+			Register:                  src.Spec.Deployment.Register,
+			AddressAllocationStrategy: convertAddressAllocationStrategyFrom(src.Spec.Deployment.AddressAllocationStrategy),
+		}
+	}
+	x.Status.ConditionedStatus = src.Status.ConditionedStatus
+	if src.Status.Deployment != nil {
+		x.Status.Deployment = &NddrOrgDeployment{
+			Register:                  convertRegisterFrom(src.Status.Deployment.Register),
+			AddressAllocationStrategy: newOrgAddressAllocationStrategy(src.Status.Deployment.AddressAllocationStrategy),
+			State:                     convertStateFrom(src.Status.Deployment.State),
+		}
+	}
+	return setConversionData(x, &v1alpha2.Deployment{Spec: src.Spec, Status: v1alpha2.DeploymentStatus{Deployment: src.Status.Deployment}})
+}
+
+// setConversionData stores the spec and status of the hub object in the
+// conversion data annotation of o.
+func setConversionData(o metav1.Object, hub interface{}) error {
+	data, err := json.Marshal(hub)
+	if err != nil {
+		return err
+	}
+	// the annotations are shared with the hub object
+	annotations := make(map[string]string, len(o.GetAnnotations())+1)
+	for k, v := range o.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[ConversionDataAnnotation] = string(data)
+	o.SetAnnotations(annotations)
+	return nil
+}
+
+// getConversionData decodes the conversion data annotation of o into hub and
+// removes the annotation from o. It returns false when o has no conversion
+// data.
+func getConversionData(o metav1.Object, hub interface{}) (bool, error) {
+	data, ok := o.GetAnnotations()[ConversionDataAnnotation]
+	if !ok {
+		return false, nil
+	}
+	// the annotations are shared with the spoke object
+	annotations := make(map[string]string, len(o.GetAnnotations()))
+	for k, v := range o.GetAnnotations() {
+		if k != ConversionDataAnnotation {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	o.SetAnnotations(annotations)
+	return true, json.Unmarshal([]byte(data), hub)
+}
+
+func equalString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func convertStateTo(s *NddrOrgDeploymentState) *v1alpha2.NddrOrgDeploymentState {
+	if s == nil {
+		return nil
+	}
+	return &v1alpha2.NddrOrgDeploymentState{
+		Reason: s.Reason,
+		Status: s.Status,
+	}
+}
+
+func convertStateFrom(s *v1alpha2.NddrOrgDeploymentState) *NddrOrgDeploymentState {
+	if s == nil {
+		return nil
+	}
+	return &NddrOrgDeploymentState{
+		Reason: s.Reason,
+		Status: s.Status,
+	}
+}
+
+func convertRegisterTo(r []*nddov1.Register) []*v1alpha2.NddrOrgRegister {
+	if r == nil {
+		return nil
+	}
+	registers := make([]*v1alpha2.NddrOrgRegister, 0, len(r))
+	for _, register := range r {
+		registers = append(registers, &v1alpha2.NddrOrgRegister{
+			Kind: register.Kind,
+			Name: register.Name,
+		})
+	}
+	return registers
+}
+
+func convertRegisterFrom(r []*v1alpha2.NddrOrgRegister) []*nddov1.Register {
+	if r == nil {
+		return nil
+	}
+	registers := make([]*nddov1.Register, 0, len(r))
+	for _, register := range r {
+		registers = append(registers, &nddov1.Register{
+			Kind: register.Kind,
+			Name: register.Name,
+		})
+	}
+	return registers
+}
+
+func convertAddressAllocationStrategyTo(a *OrgAddressAllocationStrategy) *v1alpha2.OrgAddressAllocationStrategy {
+	if a == nil {
+		return nil
+	}
+	return &v1alpha2.OrgAddressAllocationStrategy{
+		GatewayAllocation:          a.GatewayAllocation,
+		InfraItfcePrefixLengthIpv4: a.InfraItfcePrefixLengthIpv4,
+		InfraItfcePrefixLengthIpv6: a.InfraItfcePrefixLengthIpv6,
+	}
+}
+
+func convertAddressAllocationStrategyFrom(a *v1alpha2.OrgAddressAllocationStrategy) *OrgAddressAllocationStrategy {
+	if a == nil {
+		return nil
+	}
+	return &OrgAddressAllocationStrategy{
+		GatewayAllocation:          a.GatewayAllocation,
+		InfraItfcePrefixLengthIpv4: a.InfraItfcePrefixLengthIpv4,
+		InfraItfcePrefixLengthIpv6: a.InfraItfcePrefixLengthIpv6,
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	nddv1 "github.com/yndd/ndd-runtime/apis/common/v1"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	"github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func uint32Ptr(v uint32) *uint32 { return &v }
+
+func gatewayAllocationPtr(v nddov1.GatewayAllocation) *nddov1.GatewayAllocation { return &v }
+
+func TestOrganizationRoundTrip(t *testing.T) {
+	cases := map[string]*v1alpha2.Organization{
+		"Empty": {
+			ObjectMeta: metav1.ObjectMeta{Name: "nokia", Namespace: "default"},
+		},
+		"AllFields": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "nokia",
+				Namespace:   "default",
+				Annotations: map[string]string{"a": "b"},
+			},
+			Spec: v1alpha2.OrganizationSpec{
+				Organization: &v1alpha2.OrgOrganization{
+					AdminState:     utils.StringPtr("disable"),
+					Description:    utils.StringPtr("nokia organization"),
+					DeletionPolicy: utils.StringPtr(v1alpha2.DeletionPolicyCascade),
+					Register: []*nddov1.Register{
+						{Kind: utils.StringPtr("ipam"), Name: utils.StringPtr("nokia-ipam")},
+					},
+					AddressAllocationStrategy: &nddov1.AddressAllocationStrategy{
+						GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationLast),
+						InfraItfcePrefixLengthIpv4: uint32Ptr(31),
+						InfraItfcePrefixLengthIpv6: uint32Ptr(127),
+					},
+					RequiredRegisters: []string{"ipam", "as"},
+					DeploymentRequiredRegisters: []*v1alpha2.OrgDeploymentRequiredRegisters{
+						{DeploymentKind: utils.StringPtr("wan"), RequiredRegisters: []string{"ipam"}},
+					},
+					RegistryCredentials: &v1alpha2.OrgRegistryCredentials{
+						CASecretName: utils.StringPtr("registry-ca"),
+						SkipVerify:   utils.BoolPtr(true),
+					},
+				},
+			},
+			Status: v1alpha2.OrganizationStatus{
+				ConditionedStatus: nddv1.ConditionedStatus{Conditions: []nddv1.Condition{nddv1.Available()}},
+				Organization: &v1alpha2.NddrOrganization{
+					Register: []*nddov1.Register{
+						{Kind: utils.StringPtr("ipam"), Name: utils.StringPtr("nokia-ipam")},
+					},
+					State: &v1alpha2.NddrOrgDeploymentState{
+						Status: utils.StringPtr("down"),
+						Reason: utils.StringPtr("deployments block the deletion"),
+					},
+					BlockingDeployments: []string{"nokia.region1"},
+					RequiredRegisters:   []string{"ipam", "as"},
+				},
+			},
+		},
+	}
+
+	for name, want := range cases {
+		t.Run(name, func(t *testing.T) {
+			spoke := &Organization{}
+			if err := spoke.ConvertFrom(want.DeepCopy()); err != nil {
+				t.Fatalf("ConvertFrom(...): %v", err)
+			}
+			got := &v1alpha2.Organization{}
+			if err := spoke.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("round trip: -want, +got:\n%s", diff)
+			}
+			if _, ok := spoke.GetAnnotations()[ConversionDataAnnotation]; !ok {
+				t.Errorf("ConvertFrom(...): missing %s annotation", ConversionDataAnnotation)
+			}
+		})
+	}
+}
+
+func TestDeploymentRoundTrip(t *testing.T) {
+	cases := map[string]*v1alpha2.Deployment{
+		"Empty": {
+			ObjectMeta: metav1.ObjectMeta{Name: "region1", Namespace: "default"},
+		},
+		"AllFields": {
+			// the organization reference can not be derived from the name
+			ObjectMeta: metav1.ObjectMeta{Name: "region1", Namespace: "default"},
+			Spec: v1alpha2.DeploymentSpec{
+				Deployment: &v1alpha2.OrgDeployment{
+					AdminState:      utils.StringPtr("enable"),
+					Description:     utils.StringPtr("region1 deployment"),
+					OrganizationRef: utils.StringPtr("nokia"),
+					Region:          utils.StringPtr("region1"),
+					Kind:            utils.StringPtr("dc"),
+					Register: []*nddov1.Register{
+						{Kind: utils.StringPtr("as"), Name: utils.StringPtr("region1-as")},
+					},
+					AddressAllocationStrategy: &v1alpha2.OrgAddressAllocationStrategy{
+						InfraItfcePrefixLengthIpv6: uint32Ptr(64),
+					},
+				},
+			},
+			Status: v1alpha2.DeploymentStatus{
+				ConditionedStatus: nddv1.ConditionedStatus{Conditions: []nddv1.Condition{nddv1.Available()}},
+				Deployment: &v1alpha2.NddrOrgDeployment{
+					Register: []*v1alpha2.NddrOrgRegister{
+						{Kind: utils.StringPtr("as"), Name: utils.StringPtr("region1-as"), Source: utils.StringPtr(v1alpha2.RegisterSourceDeployment)},
+						{Kind: utils.StringPtr("ipam"), Name: utils.StringPtr("nokia-ipam"), Source: utils.StringPtr(v1alpha2.RegisterSourceOrganization)},
+					},
+					AddressAllocationStrategy: &nddov1.AddressAllocationStrategy{
+						GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationFirst),
+						InfraItfcePrefixLengthIpv4: uint32Ptr(31),
+						InfraItfcePrefixLengthIpv6: uint32Ptr(64),
+					},
+					AddressAllocationStrategySource: &v1alpha2.NddrOrgAddressAllocationStrategySource{
+						GatewayAllocation:          utils.StringPtr(v1alpha2.RegisterSourceOrganization),
+						InfraItfcePrefixLengthIpv4: utils.StringPtr(v1alpha2.RegisterSourceOrganization),
+						InfraItfcePrefixLengthIpv6: utils.StringPtr(v1alpha2.RegisterSourceDeployment),
+					},
+					State: &v1alpha2.NddrOrgDeploymentState{
+						Status: utils.StringPtr("up"),
+						Reason: utils.StringPtr(""),
+					},
+					RequiredRegisters: []string{"ipam", "as"},
+				},
+			},
+		},
+	}
+
+	for name, want := range cases {
+		t.Run(name, func(t *testing.T) {
+			spoke := &Deployment{}
+			if err := spoke.ConvertFrom(want.DeepCopy()); err != nil {
+				t.Fatalf("ConvertFrom(...): %v", err)
+			}
+			got := &v1alpha2.Deployment{}
+			if err := spoke.ConvertTo(got); err != nil {
+				t.Fatalf("ConvertTo(...): %v", err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("round trip: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeploymentConvertToWithoutConversionData(t *testing.T) {
+	spoke := &Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "nokia.region1", Namespace: "default"},
+		Spec: DeploymentSpec{
+			Deployment: &OrgDeployment{Kind: utils.StringPtr("dc")},
+		},
+	}
+	got := &v1alpha2.Deployment{}
+	if err := spoke.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo(...): %v", err)
+	}
+	if want := "nokia"; got.GetOrganizationName() != want {
+		t.Errorf("ConvertTo(...): organization %q, want %q", got.GetOrganizationName(), want)
+	}
+}
@@ -142,7 +142,7 @@ func (x *Deployment) GetAddressAllocationStrategy() *nddov1.AddressAllocationStr
 	if reflect.ValueOf(x.Spec.Deployment.AddressAllocationStrategy).IsZero() {
 		return &nddov1.AddressAllocationStrategy{}
 	}
-	return x.Spec.Deployment.AddressAllocationStrategy
+	return x.Spec.Deployment.AddressAllocationStrategy.addressAllocationStrategy()
 }
 
 func (x *Deployment) InitializeResource() error {
@@ -154,7 +154,7 @@ func (x *Deployment) InitializeResource() error {
 
 	x.Status.Deployment = &NddrOrgDeployment{
 		Register:                  make([]*nddov1.Register, 0),
-		AddressAllocationStrategy: &nddov1.AddressAllocationStrategy{},
+		AddressAllocationStrategy: &OrgAddressAllocationStrategy{},
 		State: &NddrOrgDeploymentState{
 			Status: utils.StringPtr(""),
 			Reason: utils.StringPtr(""),
@@ -201,12 +201,38 @@ func (x *Deployment) SetStateRegister(r map[string]string) {
 }
 
 func (x *Deployment) GetStateAddressAllocationStrategy() *nddov1.AddressAllocationStrategy {
-	if x.Status.Deployment != nil {
-		return x.Status.Deployment.AddressAllocationStrategy
+	if x.Status.Deployment != nil && x.Status.Deployment.AddressAllocationStrategy != nil {
+		return x.Status.Deployment.AddressAllocationStrategy.addressAllocationStrategy()
 	}
 	return &nddov1.AddressAllocationStrategy{}
 }
 
 func (x *Deployment) SetStateAddressAllocationStrategy(a *nddov1.AddressAllocationStrategy) {
-	x.Status.Deployment.AddressAllocationStrategy = a
+	x.Status.Deployment.AddressAllocationStrategy = newOrgAddressAllocationStrategy(a)
+}
+
+// addressAllocationStrategy returns the strategy as the common address
+// allocation strategy.
+func (a *OrgAddressAllocationStrategy) addressAllocationStrategy() *nddov1.AddressAllocationStrategy {
+	if a == nil {
+		return nil
+	}
+	return &nddov1.AddressAllocationStrategy{
+		GatewayAllocation:          a.GatewayAllocation,
+		InfraItfcePrefixLengthIpv4: a.InfraItfcePrefixLengthIpv4,
+		InfraItfcePrefixLengthIpv6: a.InfraItfcePrefixLengthIpv6,
+	}
+}
+
+// newOrgAddressAllocationStrategy returns the deployment strategy of the
+// common address allocation strategy.
+func newOrgAddressAllocationStrategy(a *nddov1.AddressAllocationStrategy) *OrgAddressAllocationStrategy {
+	if a == nil {
+		return nil
+	}
+	return &OrgAddressAllocationStrategy{
+		GatewayAllocation:          a.GatewayAllocation,
+		InfraItfcePrefixLengthIpv4: a.InfraItfcePrefixLengthIpv4,
+		InfraItfcePrefixLengthIpv6: a.InfraItfcePrefixLengthIpv6,
+	}
 }
@@ -33,9 +33,9 @@ const (
 )
 
 type NddrOrgDeployment struct {
-	Register                  []*nddov1.Register                `json:"register,omitempty"`
-	AddressAllocationStrategy *nddov1.AddressAllocationStrategy `json:"address-allocation-strategy,omitempty"`
-	State                     *NddrOrgDeploymentState           `json:"state,omitempty"`
+	Register                  []*nddov1.Register            `json:"register,omitempty"`
+	AddressAllocationStrategy *OrgAddressAllocationStrategy `json:"address-allocation-strategy,omitempty"`
+	State                     *NddrOrgDeploymentState       `json:"state,omitempty"`
 }
 
 type NddrOrgDeploymentState struct {
@@ -56,9 +56,19 @@ type OrgDeployment struct {
 	Region      *string `json:"region,omitempty"`
 	// +kubebuilder:validation:Enum=`dc`;`wan`
 	// +kubebuilder:default:="dc"
-	Kind                      *string                           `json:"kind,omitempty"`
-	Register                  []*nddov1.Register                `json:"register,omitempty"`
-	AddressAllocationStrategy *nddov1.AddressAllocationStrategy `json:"address-allocation-strategy,omitempty"`
+	Kind                      *string                       `json:"kind,omitempty"`
+	Register                  []*nddov1.Register            `json:"register,omitempty"`
+	AddressAllocationStrategy *OrgAddressAllocationStrategy `json:"address-allocation-strategy,omitempty"`
+}
+
+// OrgAddressAllocationStrategy is the address allocation strategy of a
+// deployment. It has no defaults since fields that are not set are inherited
+// from the organization.
+type OrgAddressAllocationStrategy struct {
+	// +kubebuilder:validation:Enum=`first`;`last`
+	GatewayAllocation          *nddov1.GatewayAllocation `json:"gateway-allocation,omitempty"`
+	InfraItfcePrefixLengthIpv4 *uint32                   `json:"infra-interface-prefixlength-ipv4,omitempty"`
+	InfraItfcePrefixLengthIpv6 *uint32                   `json:"infra-interface-prefixlength-ipv6,omitempty"`
 }
 
 // A DeploymentSpec defines the desired state of a Deployment.
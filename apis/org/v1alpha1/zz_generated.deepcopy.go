@@ -142,7 +142,7 @@ func (in *NddrOrgDeployment) DeepCopyInto(out *NddrOrgDeployment) {
 	}
 	if in.AddressAllocationStrategy != nil {
 		in, out := &in.AddressAllocationStrategy, &out.AddressAllocationStrategy
-		*out = new(v1.AddressAllocationStrategy)
+		*out = new(OrgAddressAllocationStrategy)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.State != nil {
@@ -248,6 +248,36 @@ func (in *NddrOrganizationState) DeepCopy() *NddrOrganizationState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrgAddressAllocationStrategy) DeepCopyInto(out *OrgAddressAllocationStrategy) {
+	*out = *in
+	if in.GatewayAllocation != nil {
+		in, out := &in.GatewayAllocation, &out.GatewayAllocation
+		*out = new(v1.GatewayAllocation)
+		**out = **in
+	}
+	if in.InfraItfcePrefixLengthIpv4 != nil {
+		in, out := &in.InfraItfcePrefixLengthIpv4, &out.InfraItfcePrefixLengthIpv4
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.InfraItfcePrefixLengthIpv6 != nil {
+		in, out := &in.InfraItfcePrefixLengthIpv6, &out.InfraItfcePrefixLengthIpv6
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrgAddressAllocationStrategy.
+func (in *OrgAddressAllocationStrategy) DeepCopy() *OrgAddressAllocationStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(OrgAddressAllocationStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OrgDeployment) DeepCopyInto(out *OrgDeployment) {
 	*out = *in
@@ -284,7 +314,7 @@ func (in *OrgDeployment) DeepCopyInto(out *OrgDeployment) {
 	}
 	if in.AddressAllocationStrategy != nil {
 		in, out := &in.AddressAllocationStrategy, &out.AddressAllocationStrategy
-		*out = new(v1.AddressAllocationStrategy)
+		*out = new(OrgAddressAllocationStrategy)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 Wim Henderickx.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//+kubebuilder:object:generate=true
+package v1alpha2
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	nddv1 "github.com/yndd/ndd-runtime/apis/common/v1"
+)
+
+// Condition Kinds.
+const (
+	// A ConditionKindAllocationReady indicates whether the allocation is ready.
+	ConditionKindReady nddv1.ConditionKind = "Ready"
+	// A ConditionKindRegisterOverride indicates whether a deployment overrides
+	// registers of its organization.
+	ConditionKindRegisterOverride nddv1.ConditionKind = "RegisterOverride"
+	// A ConditionKindRequiredRegisters indicates whether all required
+	// registers are present.
+	ConditionKindRequiredRegisters nddv1.ConditionKind = "RequiredRegisters"
+)
+
+// ConditionReasons a package is or is not installed.
+const (
+	ConditionReasonReady            nddv1.ConditionReason = "Ready"
+	ConditionReasonNotReady         nddv1.ConditionReason = "NotReady"
+	ConditionReasonAllocating       nddv1.ConditionReason = "Allocating"
+	ConditionReasonDeAllocating     nddv1.ConditionReason = "DeAllocating"
+	ConditionReasonOverride         nddv1.ConditionReason = "Override"
+	ConditionReasonNoOverride       nddv1.ConditionReason = "NoOverride"
+	ConditionReasonRegistersPresent nddv1.ConditionReason = "RegistersPresent"
+	ConditionReasonRegistersMissing nddv1.ConditionReason = "RegistersMissing"
+
+	ConditionReasonAdminDisabled             nddv1.ConditionReason = "AdminDisabled"
+	ConditionReasonOrganizationAdminDisabled nddv1.ConditionReason = "OrganizationAdminDisabled"
+	ConditionReasonOrganizationNotFound      nddv1.ConditionReason = "OrganizationNotFound"
+)
+
+// Ready indicates that the resource is ready.
+func Ready() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindReady,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonReady,
+	}
+}
+
+// NotReady indicates that the resource is not ready.
+func NotReady() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonNotReady,
+	}
+}
+
+// RegisterOverride indicates that the deployment overrides the registers of
+// the supplied kinds of its organization.
+func RegisterOverride(kinds []string) nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRegisterOverride,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonOverride,
+		Message:            "organization registers overridden: " + strings.Join(kinds, ", "),
+	}
+}
+
+// NoRegisterOverride indicates that the deployment does not override any
+// register of its organization.
+func NoRegisterOverride() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRegisterOverride,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonNoOverride,
+	}
+}
+
+// RequiredRegistersPresent indicates that all required registers are present.
+func RequiredRegistersPresent() nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRequiredRegisters,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonRegistersPresent,
+	}
+}
+
+// RequiredRegistersMissing indicates that the required registers of the
+// supplied kinds are missing.
+func RequiredRegistersMissing(kinds []string) nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRequiredRegisters,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ConditionReasonRegistersMissing,
+		Message:            "required registers missing: " + strings.Join(kinds, ", "),
+	}
+}
+
+// RequiredRegistersNotEvaluated indicates that the required registers are not
+// evaluated for the supplied reason, e.g. since the resource is disabled. It is
+// not a condition kind of its own: it sets the RequiredRegisters condition to
+// Unknown, replacing a stale Present or Missing state.
+func RequiredRegistersNotEvaluated(reason nddv1.ConditionReason) nddv1.Condition {
+	return nddv1.Condition{
+		Kind:               ConditionKindRequiredRegisters,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+	}
+}
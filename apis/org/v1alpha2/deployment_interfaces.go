@@ -0,0 +1,279 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"reflect"
+	"strings"
+
+	nddv1 "github.com/yndd/ndd-runtime/apis/common/v1"
+	"github.com/yndd/ndd-runtime/pkg/resource"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ DpList = &DeploymentList{}
+
+// +k8s:deepcopy-gen=false
+type DpList interface {
+	client.ObjectList
+
+	GetDeployments() []Dp
+}
+
+func (x *DeploymentList) GetDeployments() []Dp {
+	xs := make([]Dp, len(x.Items))
+	for i, r := range x.Items {
+		r := r // Pin range variable so we can take its address.
+		xs[i] = &r
+	}
+	return xs
+}
+
+var _ Dp = &Deployment{}
+
+// +k8s:deepcopy-gen=false
+type Dp interface {
+	resource.Object
+	resource.Conditioned
+
+	GetOrganizationName() string
+	GetDeploymentName() string
+	GetAdminState() string
+	GetDescription() string
+	GetKind() string
+	GetRegion() string
+	GetRegister() map[string]string
+	GetAddressAllocationStrategy() *nddov1.AddressAllocationStrategy
+	InitializeResource() error
+
+	SetStatus(string)
+	SetReason(string)
+	GetStatus() string
+	GetStateRegister() map[string]string
+	SetStateRegister(map[string]string)
+	GetStateRegisterSource() map[string]string
+	SetStateRegisterWithSource(map[string]string, map[string]string)
+	GetStateAddressAllocationStrategy() *nddov1.AddressAllocationStrategy
+	SetStateAddressAllocationStrategy(*nddov1.AddressAllocationStrategy)
+	GetStateAddressAllocationStrategySource() *NddrOrgAddressAllocationStrategySource
+	SetStateAddressAllocationStrategySource(*NddrOrgAddressAllocationStrategySource)
+	GetStateRequiredRegisters() []string
+	SetStateRequiredRegisters([]string)
+}
+
+// GetCondition of this Network Node.
+func (x *Deployment) GetCondition(ct nddv1.ConditionKind) nddv1.Condition {
+	return x.Status.GetCondition(ct)
+}
+
+// SetConditions of the Network Node.
+func (x *Deployment) SetConditions(c ...nddv1.Condition) {
+	x.Status.SetConditions(c...)
+}
+
+// deployment returns the deployment spec, or an empty spec when it is not set;
+// spec.deployment is optional in the crd.
+func (x *Deployment) deployment() *OrgDeployment {
+	if x.Spec.Deployment == nil {
+		return &OrgDeployment{}
+	}
+	return x.Spec.Deployment
+}
+
+func (x *Deployment) GetOrganizationName() string {
+	if reflect.ValueOf(x.deployment().OrganizationRef).IsZero() {
+		return ""
+	}
+	return *x.deployment().OrganizationRef
+}
+
+// GetDeploymentName returns the name of the deployment within its organization;
+// when the object name is prefixed with the organization name the prefix is stripped.
+func (x *Deployment) GetDeploymentName() string {
+	if orgName := x.GetOrganizationName(); orgName != "" {
+		return strings.TrimPrefix(x.GetName(), orgName+".")
+	}
+	return x.GetName()
+}
+
+func (x *Deployment) GetAdminState() string {
+	if reflect.ValueOf(x.deployment().AdminState).IsZero() {
+		return ""
+	}
+	return *x.deployment().AdminState
+}
+
+func (x *Deployment) GetDescription() string {
+	if reflect.ValueOf(x.deployment().Description).IsZero() {
+		return ""
+	}
+	return *x.deployment().Description
+}
+
+func (x *Deployment) GetKind() string {
+	if reflect.ValueOf(x.deployment().Kind).IsZero() {
+		return ""
+	}
+	return *x.deployment().Kind
+}
+
+func (x *Deployment) GetRegion() string {
+	if reflect.ValueOf(x.deployment().Region).IsZero() {
+		return ""
+	}
+	return *x.deployment().Region
+}
+
+func (x *Deployment) GetRegister() map[string]string {
+	s := make(map[string]string)
+	if reflect.ValueOf(x.deployment().Register).IsZero() {
+		return s
+	}
+	for _, register := range x.deployment().Register {
+		for kind, name := range register.GetRegister() {
+			s[kind] = name
+		}
+	}
+	return s
+}
+
+// GetAddressAllocationStrategy returns the address allocation strategy of the
+// deployment; fields that are not set are nil.
+func (x *Deployment) GetAddressAllocationStrategy() *nddov1.AddressAllocationStrategy {
+	if reflect.ValueOf(x.deployment().AddressAllocationStrategy).IsZero() {
+		return &nddov1.AddressAllocationStrategy{}
+	}
+	return &nddov1.AddressAllocationStrategy{
+		GatewayAllocation:          x.deployment().AddressAllocationStrategy.GatewayAllocation,
+		InfraItfcePrefixLengthIpv4: x.deployment().AddressAllocationStrategy.InfraItfcePrefixLengthIpv4,
+		InfraItfcePrefixLengthIpv6: x.deployment().AddressAllocationStrategy.InfraItfcePrefixLengthIpv6,
+	}
+}
+
+func (x *Deployment) InitializeResource() error {
+	if x.Status.Deployment != nil {
+		// resource was already initialiazed
+		// copy the spec, but not the state
+		return nil
+	}
+
+	x.Status.Deployment = &NddrOrgDeployment{
+		Register:                  make([]*NddrOrgRegister, 0),
+		AddressAllocationStrategy: &nddov1.AddressAllocationStrategy{},
+		State: &NddrOrgDeploymentState{
+			Status: utils.StringPtr(""),
+			Reason: utils.StringPtr(""),
+		},
+	}
+	return nil
+}
+
+func (x *Deployment) SetStatus(s string) {
+	x.Status.Deployment.State.Status = &s
+}
+
+func (x *Deployment) SetReason(s string) {
+	x.Status.Deployment.State.Reason = &s
+}
+
+func (x *Deployment) GetStatus() string {
+	if x.Status.Deployment != nil && x.Status.Deployment.State != nil && x.Status.Deployment.State.Status != nil {
+		return *x.Status.Deployment.State.Status
+	}
+	return "unknown"
+}
+
+func (x *Deployment) GetStateRegister() map[string]string {
+	r := make(map[string]string)
+	if x.Status.Deployment != nil && x.Status.Deployment.State != nil && x.Status.Deployment.State.Status != nil {
+		for _, register := range x.Status.Deployment.Register {
+			if register.Kind != nil && register.Name != nil {
+				r[*register.Kind] = *register.Name
+			}
+		}
+	}
+	return r
+}
+
+func (x *Deployment) SetStateRegister(r map[string]string) {
+	x.SetStateRegisterWithSource(r, nil)
+}
+
+// GetStateRegisterSource returns the source per register kind of the
+// effective registers.
+func (x *Deployment) GetStateRegisterSource() map[string]string {
+	s := make(map[string]string)
+	if x.Status.Deployment != nil {
+		for _, register := range x.Status.Deployment.Register {
+			if register.Kind != nil && register.Source != nil {
+				s[*register.Kind] = *register.Source
+			}
+		}
+	}
+	return s
+}
+
+// SetStateRegisterWithSource sets the effective registers together with the
+// source per register kind.
+func (x *Deployment) SetStateRegisterWithSource(r map[string]string, source map[string]string) {
+	x.Status.Deployment.Register = make([]*NddrOrgRegister, 0, len(r))
+	for _, kind := range sortedKinds(r) {
+		register := &NddrOrgRegister{
+			Kind: utils.StringPtr(kind),
+			Name: utils.StringPtr(r[kind]),
+		}
+		if s, ok := source[kind]; ok {
+			register.Source = utils.StringPtr(s)
+		}
+		x.Status.Deployment.Register = append(x.Status.Deployment.Register, register)
+	}
+}
+
+func (x *Deployment) GetStateAddressAllocationStrategy() *nddov1.AddressAllocationStrategy {
+	if x.Status.Deployment != nil {
+		return x.Status.Deployment.AddressAllocationStrategy
+	}
+	return &nddov1.AddressAllocationStrategy{}
+}
+
+func (x *Deployment) SetStateAddressAllocationStrategy(a *nddov1.AddressAllocationStrategy) {
+	x.Status.Deployment.AddressAllocationStrategy = a
+}
+
+func (x *Deployment) GetStateAddressAllocationStrategySource() *NddrOrgAddressAllocationStrategySource {
+	if x.Status.Deployment != nil && x.Status.Deployment.AddressAllocationStrategySource != nil {
+		return x.Status.Deployment.AddressAllocationStrategySource
+	}
+	return &NddrOrgAddressAllocationStrategySource{}
+}
+
+func (x *Deployment) SetStateAddressAllocationStrategySource(s *NddrOrgAddressAllocationStrategySource) {
+	x.Status.Deployment.AddressAllocationStrategySource = s
+}
+
+func (x *Deployment) GetStateRequiredRegisters() []string {
+	if x.Status.Deployment != nil {
+		return x.Status.Deployment.RequiredRegisters
+	}
+	return nil
+}
+
+func (x *Deployment) SetStateRequiredRegisters(r []string) {
+	x.Status.Deployment.RequiredRegisters = r
+}
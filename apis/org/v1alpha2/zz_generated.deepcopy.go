@@ -0,0 +1,647 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"github.com/yndd/nddo-runtime/apis/common/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Deployment) DeepCopyInto(out *Deployment) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deployment.
+func (in *Deployment) DeepCopy() *Deployment {
+	if in == nil {
+		return nil
+	}
+	out := new(Deployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Deployment) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentList) DeepCopyInto(out *DeploymentList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Deployment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentList.
+func (in *DeploymentList) DeepCopy() *DeploymentList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentSpec) DeepCopyInto(out *DeploymentSpec) {
+	*out = *in
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(OrgDeployment)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentSpec.
+func (in *DeploymentSpec) DeepCopy() *DeploymentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentStatus) DeepCopyInto(out *DeploymentStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(NddrOrgDeployment)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentStatus.
+func (in *DeploymentStatus) DeepCopy() *DeploymentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NddrOrgAddressAllocationStrategySource) DeepCopyInto(out *NddrOrgAddressAllocationStrategySource) {
+	*out = *in
+	if in.GatewayAllocation != nil {
+		in, out := &in.GatewayAllocation, &out.GatewayAllocation
+		*out = new(string)
+		**out = **in
+	}
+	if in.InfraItfcePrefixLengthIpv4 != nil {
+		in, out := &in.InfraItfcePrefixLengthIpv4, &out.InfraItfcePrefixLengthIpv4
+		*out = new(string)
+		**out = **in
+	}
+	if in.InfraItfcePrefixLengthIpv6 != nil {
+		in, out := &in.InfraItfcePrefixLengthIpv6, &out.InfraItfcePrefixLengthIpv6
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NddrOrgAddressAllocationStrategySource.
+func (in *NddrOrgAddressAllocationStrategySource) DeepCopy() *NddrOrgAddressAllocationStrategySource {
+	if in == nil {
+		return nil
+	}
+	out := new(NddrOrgAddressAllocationStrategySource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NddrOrgDeployment) DeepCopyInto(out *NddrOrgDeployment) {
+	*out = *in
+	if in.Register != nil {
+		in, out := &in.Register, &out.Register
+		*out = make([]*NddrOrgRegister, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(NddrOrgRegister)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.AddressAllocationStrategy != nil {
+		in, out := &in.AddressAllocationStrategy, &out.AddressAllocationStrategy
+		*out = new(v1.AddressAllocationStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AddressAllocationStrategySource != nil {
+		in, out := &in.AddressAllocationStrategySource, &out.AddressAllocationStrategySource
+		*out = new(NddrOrgAddressAllocationStrategySource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.State != nil {
+		in, out := &in.State, &out.State
+		*out = new(NddrOrgDeploymentState)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequiredRegisters != nil {
+		in, out := &in.RequiredRegisters, &out.RequiredRegisters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NddrOrgDeployment.
+func (in *NddrOrgDeployment) DeepCopy() *NddrOrgDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(NddrOrgDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NddrOrgDeploymentState) DeepCopyInto(out *NddrOrgDeploymentState) {
+	*out = *in
+	if in.Reason != nil {
+		in, out := &in.Reason, &out.Reason
+		*out = new(string)
+		**out = **in
+	}
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NddrOrgDeploymentState.
+func (in *NddrOrgDeploymentState) DeepCopy() *NddrOrgDeploymentState {
+	if in == nil {
+		return nil
+	}
+	out := new(NddrOrgDeploymentState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NddrOrgRegister) DeepCopyInto(out *NddrOrgRegister) {
+	*out = *in
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NddrOrgRegister.
+func (in *NddrOrgRegister) DeepCopy() *NddrOrgRegister {
+	if in == nil {
+		return nil
+	}
+	out := new(NddrOrgRegister)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NddrOrganization) DeepCopyInto(out *NddrOrganization) {
+	*out = *in
+	if in.Register != nil {
+		in, out := &in.Register, &out.Register
+		*out = make([]*v1.Register, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(v1.Register)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.AddressAllocationStrategy != nil {
+		in, out := &in.AddressAllocationStrategy, &out.AddressAllocationStrategy
+		*out = new(v1.AddressAllocationStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.State != nil {
+		in, out := &in.State, &out.State
+		*out = new(NddrOrgDeploymentState)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlockingDeployments != nil {
+		in, out := &in.BlockingDeployments, &out.BlockingDeployments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredRegisters != nil {
+		in, out := &in.RequiredRegisters, &out.RequiredRegisters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NddrOrganization.
+func (in *NddrOrganization) DeepCopy() *NddrOrganization {
+	if in == nil {
+		return nil
+	}
+	out := new(NddrOrganization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NddrOrganizationState) DeepCopyInto(out *NddrOrganizationState) {
+	*out = *in
+	if in.Reason != nil {
+		in, out := &in.Reason, &out.Reason
+		*out = new(string)
+		**out = **in
+	}
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NddrOrganizationState.
+func (in *NddrOrganizationState) DeepCopy() *NddrOrganizationState {
+	if in == nil {
+		return nil
+	}
+	out := new(NddrOrganizationState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrgAddressAllocationStrategy) DeepCopyInto(out *OrgAddressAllocationStrategy) {
+	*out = *in
+	if in.GatewayAllocation != nil {
+		in, out := &in.GatewayAllocation, &out.GatewayAllocation
+		*out = new(v1.GatewayAllocation)
+		**out = **in
+	}
+	if in.InfraItfcePrefixLengthIpv4 != nil {
+		in, out := &in.InfraItfcePrefixLengthIpv4, &out.InfraItfcePrefixLengthIpv4
+		*out = new(uint32)
+		**out = **in
+	}
+	if in.InfraItfcePrefixLengthIpv6 != nil {
+		in, out := &in.InfraItfcePrefixLengthIpv6, &out.InfraItfcePrefixLengthIpv6
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrgAddressAllocationStrategy.
+func (in *OrgAddressAllocationStrategy) DeepCopy() *OrgAddressAllocationStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(OrgAddressAllocationStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrgDeployment) DeepCopyInto(out *OrgDeployment) {
+	*out = *in
+	if in.AdminState != nil {
+		in, out := &in.AdminState, &out.AdminState
+		*out = new(string)
+		**out = **in
+	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.OrganizationRef != nil {
+		in, out := &in.OrganizationRef, &out.OrganizationRef
+		*out = new(string)
+		**out = **in
+	}
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Register != nil {
+		in, out := &in.Register, &out.Register
+		*out = make([]*v1.Register, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(v1.Register)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.AddressAllocationStrategy != nil {
+		in, out := &in.AddressAllocationStrategy, &out.AddressAllocationStrategy
+		*out = new(OrgAddressAllocationStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrgDeployment.
+func (in *OrgDeployment) DeepCopy() *OrgDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(OrgDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrgDeploymentRequiredRegisters) DeepCopyInto(out *OrgDeploymentRequiredRegisters) {
+	*out = *in
+	if in.DeploymentKind != nil {
+		in, out := &in.DeploymentKind, &out.DeploymentKind
+		*out = new(string)
+		**out = **in
+	}
+	if in.RequiredRegisters != nil {
+		in, out := &in.RequiredRegisters, &out.RequiredRegisters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrgDeploymentRequiredRegisters.
+func (in *OrgDeploymentRequiredRegisters) DeepCopy() *OrgDeploymentRequiredRegisters {
+	if in == nil {
+		return nil
+	}
+	out := new(OrgDeploymentRequiredRegisters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrgOrganization) DeepCopyInto(out *OrgOrganization) {
+	*out = *in
+	if in.AdminState != nil {
+		in, out := &in.AdminState, &out.AdminState
+		*out = new(string)
+		**out = **in
+	}
+	if in.Description != nil {
+		in, out := &in.Description, &out.Description
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.Register != nil {
+		in, out := &in.Register, &out.Register
+		*out = make([]*v1.Register, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(v1.Register)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.AddressAllocationStrategy != nil {
+		in, out := &in.AddressAllocationStrategy, &out.AddressAllocationStrategy
+		*out = new(v1.AddressAllocationStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequiredRegisters != nil {
+		in, out := &in.RequiredRegisters, &out.RequiredRegisters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeploymentRequiredRegisters != nil {
+		in, out := &in.DeploymentRequiredRegisters, &out.DeploymentRequiredRegisters
+		*out = make([]*OrgDeploymentRequiredRegisters, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(OrgDeploymentRequiredRegisters)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.RegistryCredentials != nil {
+		in, out := &in.RegistryCredentials, &out.RegistryCredentials
+		*out = new(OrgRegistryCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrgOrganization.
+func (in *OrgOrganization) DeepCopy() *OrgOrganization {
+	if in == nil {
+		return nil
+	}
+	out := new(OrgOrganization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrgRegistryCredentials) DeepCopyInto(out *OrgRegistryCredentials) {
+	*out = *in
+	if in.CASecretName != nil {
+		in, out := &in.CASecretName, &out.CASecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.TLSSecretName != nil {
+		in, out := &in.TLSSecretName, &out.TLSSecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.CredentialsSecretName != nil {
+		in, out := &in.CredentialsSecretName, &out.CredentialsSecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServerName != nil {
+		in, out := &in.ServerName, &out.ServerName
+		*out = new(string)
+		**out = **in
+	}
+	if in.SkipVerify != nil {
+		in, out := &in.SkipVerify, &out.SkipVerify
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrgRegistryCredentials.
+func (in *OrgRegistryCredentials) DeepCopy() *OrgRegistryCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(OrgRegistryCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Organization) DeepCopyInto(out *Organization) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Organization.
+func (in *Organization) DeepCopy() *Organization {
+	if in == nil {
+		return nil
+	}
+	out := new(Organization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Organization) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrganizationList) DeepCopyInto(out *OrganizationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Organization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrganizationList.
+func (in *OrganizationList) DeepCopy() *OrganizationList {
+	if in == nil {
+		return nil
+	}
+	out := new(OrganizationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OrganizationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrganizationSpec) DeepCopyInto(out *OrganizationSpec) {
+	*out = *in
+	if in.Organization != nil {
+		in, out := &in.Organization, &out.Organization
+		*out = new(OrgOrganization)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrganizationSpec.
+func (in *OrganizationSpec) DeepCopy() *OrganizationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OrganizationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrganizationStatus) DeepCopyInto(out *OrganizationStatus) {
+	*out = *in
+	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.Organization != nil {
+		in, out := &in.Organization, &out.Organization
+		*out = new(NddrOrganization)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrganizationStatus.
+func (in *OrganizationStatus) DeepCopy() *OrganizationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OrganizationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/manifests"
+	"github.com/yndd/nddr-organization/internal/resolve"
+	"github.com/yndd/nddr-organization/internal/shared"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var (
+	resolveFiles  []string
+	resolveOutput string
+)
+
+// resolveCmd resolves the effective registers of deployments from manifests
+var resolveCmd = &cobra.Command{
+	Use:   "resolve",
+	Short: "resolve the effective registers of deployments from manifests",
+	Long: "resolve the effective registers and address allocation strategy of the deployments in the manifests, " +
+		"using the same defaulting and merge logic as the controller, without a cluster",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(resolveFiles) == 0 {
+			return errors.New("no manifests, specify them with -f")
+		}
+		m, err := manifests.Load(resolveFiles...)
+		if err != nil {
+			return errors.Wrap(err, "cannot load manifests")
+		}
+		o := &defaults.Options{
+			RegisterKinds:        registerKinds,
+			RegisterNameTemplate: registerNameTemplate,
+		}
+		defaultManifestsSchema(m)
+
+		deps := make([]*resolve.Deployment, 0, len(m.Deployments))
+		for _, dep := range m.Deployments {
+			var org orgv1alpha2.Org
+			if mo := m.Organization(organizationNamespace(dep.GetNamespace()), dep.GetOrganizationName()); mo != nil {
+				org = mo.Organization
+			}
+			deps = append(deps, resolve.ResolveDeployment(dep.Deployment, org, o))
+		}
+		sort.SliceStable(deps, func(i, j int) bool {
+			if deps[i].Namespace != deps[j].Namespace {
+				return deps[i].Namespace < deps[j].Namespace
+			}
+			return deps[i].Name < deps[j].Name
+		})
+		return printDeployments(cmd.OutOrStdout(), resolveOutput, deps)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resolveCmd)
+	resolveCmd.Flags().StringSliceVarP(&resolveFiles, "filename", "f", nil, "Manifest files or directories with organizations and deployments.")
+	resolveCmd.Flags().StringVarP(&resolveOutput, "output", "o", outputTable, "Output format: table, json or yaml.")
+	resolveCmd.Flags().StringSliceVarP(&registerKinds, "default-register-kinds", "", defaults.DefaultRegisterKinds, "Register kinds added to an organization when missing.")
+	resolveCmd.Flags().StringVarP(&registerNameTemplate, "default-register-name-template", "", defaults.DefaultRegisterNameTemplate, "Name of a defaulted register, {{org}} is replaced by the organization name.")
+	resolveCmd.Flags().StringVarP(&clusterOrgNamespace, "cluster-organization-namespace", "", "", "Designated namespace whose organizations govern the deployments of all namespaces, in place of cluster-scoped organizations; organizations stay namespaced and those in other namespaces govern no deployments. By default an organization only governs the deployments in its own namespace.")
+}
+
+// defaultManifestsSchema defaults the organizations and deployments in the
+// manifests as the API server defaults the resources it admits. The
+// registers and address allocation strategy are not defaulted, such that
+// the resolved deployments report the defaults as their source.
+func defaultManifestsSchema(m *manifests.Manifests) {
+	for _, org := range m.Organizations {
+		defaults.DefaultOrganizationSchema(org.Organization)
+	}
+	for _, dep := range m.Deployments {
+		defaults.DefaultDeploymentSchema(dep.Deployment)
+	}
+}
+
+// organizationNamespace returns the namespace of the organizations that
+// govern the deployments in the namespace, as the controllers do.
+func organizationNamespace(namespace string) string {
+	o := &shared.NddControllerOptions{ClusterOrganizationNamespace: clusterOrgNamespace}
+	return o.OrganizationNamespace(namespace)
+}
+
+func printDeployments(w io.Writer, output string, deps []*resolve.Deployment) error {
+	switch output {
+	case outputJSON:
+		b, err := json.MarshalIndent(deps, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case outputYAML:
+		b, err := yaml.Marshal(deps)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAMESPACE\tNAME\tORG\tSTATUS\tREASON\tREGISTER\tGATEWAY\tIPV4-PREFIXLENGTH\tIPV6-PREFIXLENGTH")
+		for _, d := range deps {
+			gw, ipv4, ipv6 := "", "", ""
+			if aas := d.AddressAllocationStrategy; aas != nil {
+				if aas.GatewayAllocation != nil {
+					gw = aas.GatewayAllocation.String()
+				}
+				if aas.InfraItfcePrefixLengthIpv4 != nil {
+					ipv4 = strconv.Itoa(int(*aas.InfraItfcePrefixLengthIpv4))
+				}
+				if aas.InfraItfcePrefixLengthIpv6 != nil {
+					ipv6 = strconv.Itoa(int(*aas.InfraItfcePrefixLengthIpv6))
+				}
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				d.Namespace, d.Name, d.Organization, d.Status, d.Reason, formatRegister(d.Register), gw, ipv4, ipv6)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unknown output format %s, use table, json or yaml", output)
+}
+
+// formatRegister returns the register as kind=name pairs sorted by kind
+func formatRegister(register map[string]string) string {
+	kinds := make([]string, 0, len(register))
+	for kind := range register {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	pairs := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		pairs = append(pairs, kind+"="+register[kind])
+	}
+	return strings.Join(pairs, ",")
+}
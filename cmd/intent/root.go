@@ -26,6 +26,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 
 	orgv1alpha1 "github.com/yndd/nddr-organization/apis/org/v1alpha1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -57,6 +58,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	//utilruntime.Must(aspoolv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(orgv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(orgv1alpha2.AddToScheme(scheme))
 	//utilruntime.Must(ndrv1.AddToScheme(scheme))
 	//utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
@@ -17,6 +17,7 @@ limitations under the License.
 package intent
 
 import (
+	"context"
 	"os"
 	"strconv"
 	"strings"
@@ -27,6 +28,7 @@ import (
 
 	pkgmetav1 "github.com/yndd/ndd-core/apis/pkg/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -35,20 +37,44 @@ import (
 	"github.com/yndd/ndd-runtime/pkg/ratelimiter"
 
 	"github.com/yndd/nddr-organization/internal/controllers"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/grpcserver"
+	"github.com/yndd/nddr-organization/internal/webhooks"
 
+	"github.com/yndd/nddr-organization/internal/requeue"
 	"github.com/yndd/nddr-organization/internal/shared"
+	"github.com/yndd/nddr-organization/internal/tracing"
+	"github.com/yndd/nddr-organization/pkg/registry"
 )
 
+// webhookPort is the port the webhook server listens on
+const webhookPort = 9443
+
 var (
-	metricsAddr          string
-	probeAddr            string
-	enableLeaderElection bool
-	concurrency          int
-	pollInterval         time.Duration
-	namespace            string
-	podname              string
-	grpcServerAddress    string
-	grpcQueryAddress     string
+	metricsAddr           string
+	probeAddr             string
+	enableLeaderElection  bool
+	concurrency           int
+	pollInterval          time.Duration
+	namespace             string
+	podname               string
+	grpcServerAddress     string
+	grpcQueryAddress      string
+	grpcServerTLS         = grpcserver.TLSOptions{}
+	grpcServerInsecure    bool
+	registerKinds         []string
+	registerNameTemplate  string
+	registryCredentials   = registry.Credentials{}
+	clusterOrgNamespace   string
+	requeueOptions        = requeue.DefaultOptions()
+	registryReadiness     bool
+	registryProbeInterval time.Duration
+	backendNamespace      string
+	backendPackages       map[string]string
+	tracingOptions        = tracing.Options{}
+	webhookProvision      bool
+	webhookServiceName    string
+	webhookCertDir        string
 )
 
 // startCmd represents the start command for the network device driver
@@ -64,11 +90,28 @@ var startCmd = &cobra.Command{
 			// Only use a logr.Logger when debug is on
 			ctrl.SetLogger(zlog)
 		}
+		if grpcServerAddress == "" && grpcQueryAddress != "" {
+			// the deprecated query address configures the grpc server
+			grpcServerAddress = grpcQueryAddress
+		}
+		shutdownTracing, err := tracing.Setup(context.Background(), tracingOptions)
+		if err != nil {
+			return errors.Wrap(err, "Cannot set up tracing")
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				zlog.Error(err, "cannot flush traces")
+			}
+		}()
+
 		zlog.Info("create manager")
 		mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 			Scheme:                 scheme,
 			MetricsBindAddress:     metricsAddr,
-			Port:                   9443,
+			Port:                   webhookPort,
+			CertDir:                webhookCertDir,
 			HealthProbeBindAddress: probeAddr,
 			//LeaderElection:         false,
 			LeaderElection:   enableLeaderElection,
@@ -82,6 +125,14 @@ var startCmd = &cobra.Command{
 			Logger:    logging.NewLogrLogger(zlog.WithName("organization")),
 			Poll:      pollInterval,
 			Namespace: namespace,
+			// organization defaults
+			RegisterKinds:        registerKinds,
+			RegisterNameTemplate: registerNameTemplate,
+			RegistryCredentials:  getRegistryCredentials(),
+			// organization scope
+			ClusterOrganizationNamespace: clusterOrgNamespace,
+			// requeue policy
+			Requeue: requeueOptions,
 		}
 
 		// initialize controllers
@@ -89,6 +140,73 @@ var startCmd = &cobra.Command{
 			return errors.Wrap(err, "Cannot add nddo controllers to manager")
 		}
 
+		// initialize webhooks
+		if err := webhooks.Setup(mgr, nddcopts); err != nil {
+			return errors.Wrap(err, "Cannot add nddo webhooks to manager")
+		}
+		if webhookProvision {
+			// the webhooks are served with the certificate in the cert dir
+			// when they cannot be provisioned, e.g. since the manager lacks
+			// the rbac to manage webhook configurations
+			if err := provisionWebhooks(mgr, nddcopts.Logger); err != nil {
+				zlog.Error(err, "cannot provision webhooks")
+			}
+		}
+
+		// the backend services are listed with a cache restricted to the
+		// backend namespace, which only needs list and watch access to the
+		// services and endpointslices in that namespace
+		backendCache, err := cache.New(mgr.GetConfig(), cache.Options{
+			Scheme:    mgr.GetScheme(),
+			Mapper:    mgr.GetRESTMapper(),
+			Namespace: backendNamespace,
+		})
+		if err != nil {
+			return errors.Wrap(err, "Cannot create registry backend cache")
+		}
+		if err := mgr.Add(backendCache); err != nil {
+			return errors.Wrap(err, "Cannot add registry backend cache to manager")
+		}
+
+		// initialize the registry
+		regOpts := []registry.Option{
+			registry.WithLogger(nddcopts.Logger),
+			registry.WithClient(mgr.GetClient()),
+			// the secrets are read without a cluster-wide secret informer
+			registry.WithAPIReader(mgr.GetAPIReader()),
+			registry.WithOrganizationNamespace(nddcopts.OrganizationNamespace),
+			registry.WithCredentials(nddcopts.RegistryCredentials),
+			registry.WithBackends(registry.NewBackends(backendNamespace, backendPackages)),
+			registry.WithBackendReader(backendCache),
+		}
+		if grpcServerAddress != "" {
+			if err := registry.IndexRegisters(context.Background(), mgr.GetFieldIndexer()); err != nil {
+				return errors.Wrap(err, "Cannot index registers")
+			}
+			regOpts = append(regOpts, registry.WithCache(mgr.GetCache()))
+		}
+		reg := registry.New(regOpts...)
+		defer reg.Close()
+
+		// initialize the registry grpc server
+		if grpcServerAddress != "" {
+			grpcOpts := []grpcserver.Option{
+				grpcserver.WithLogger(nddcopts.Logger),
+				grpcserver.WithRegistry(reg),
+			}
+			switch {
+			case grpcServerTLS.CertFile != "":
+				grpcOpts = append(grpcOpts, grpcserver.WithTLS(grpcServerTLS))
+			case grpcServerInsecure:
+				grpcOpts = append(grpcOpts, grpcserver.WithInsecure())
+			default:
+				return errors.New("the grpc server requires --grpc-server-tls-cert or --grpc-server-insecure")
+			}
+			if err := mgr.Add(grpcserver.New(grpcServerAddress, grpcOpts...)); err != nil {
+				return errors.Wrap(err, "Cannot add registry grpc server to manager")
+			}
+		}
+
 		// +kubebuilder:scaffold:builder
 
 		if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
@@ -97,6 +215,18 @@ var startCmd = &cobra.Command{
 		if err := mgr.AddReadyzCheck("check", healthz.Ping); err != nil {
 			return errors.Wrap(err, "unable to set up ready check")
 		}
+		if registryReadiness {
+			prober := registry.NewBackendProber(reg, registryProbeInterval)
+			if err := mgr.Add(prober); err != nil {
+				return errors.Wrap(err, "Cannot add registry backend prober to manager")
+			}
+			if err := mgr.AddReadyzCheck("registry", prober.ReadyzCheck()); err != nil {
+				return errors.Wrap(err, "unable to set up registry ready check")
+			}
+		}
+		if err := mgr.AddMetricsExtraHandler("/debug/registry/backends", registry.BackendStatusHandler(reg)); err != nil {
+			return errors.Wrap(err, "unable to set up registry debug endpoint")
+		}
 
 		zlog.Info("starting manager")
 		if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -118,6 +248,34 @@ func init() {
 	startCmd.Flags().StringVarP(&podname, "podname", "", os.Getenv("POD_NAME"), "Name from the pod")
 	startCmd.Flags().StringVarP(&grpcServerAddress, "grpc-server-address", "s", "", "The address of the grpc server binds to.")
 	startCmd.Flags().StringVarP(&grpcQueryAddress, "grpc-query-address", "", "", "Validation query address.")
+	_ = startCmd.Flags().MarkDeprecated("grpc-query-address", "use --grpc-server-address instead")
+	startCmd.Flags().StringVarP(&grpcServerTLS.CertFile, "grpc-server-tls-cert", "", "", "Serving certificate of the grpc server.")
+	startCmd.Flags().StringVarP(&grpcServerTLS.KeyFile, "grpc-server-tls-key", "", "", "Serving key of the grpc server.")
+	startCmd.Flags().StringVarP(&grpcServerTLS.ClientCAFile, "grpc-server-client-ca", "", "", "CA that verifies the grpc client certificates; a client may only query the namespaces listed as organizations in its certificate subject, \"*\" authorizes all namespaces.")
+	startCmd.Flags().BoolVarP(&grpcServerInsecure, "grpc-server-insecure", "", false, "Serve the grpc server without TLS and client authentication.")
+	startCmd.Flags().BoolVarP(&webhookProvision, "webhook-provision", "", false, "Provision the webhook service, a self-signed serving certificate, the webhook configurations and the crd conversion webhook at start; requires POD_NAMESPACE and rbac to manage services, secrets, webhook configurations and crds. When disabled the certificate must be present in the webhook cert dir.")
+	startCmd.Flags().StringVarP(&webhookServiceName, "webhook-service-name", "", webhooks.DefaultServiceName, "Name of the webhook service, its certificate secret and the webhook configurations.")
+	startCmd.Flags().StringVarP(&webhookCertDir, "webhook-cert-dir", "", webhooks.DefaultCertDir, "Directory with the tls.crt and tls.key served by the webhook server.")
+	startCmd.Flags().StringSliceVarP(&registerKinds, "default-register-kinds", "", defaults.DefaultRegisterKinds, "Register kinds added to an organization when missing.")
+	startCmd.Flags().StringVarP(&registerNameTemplate, "default-register-name-template", "", defaults.DefaultRegisterNameTemplate, "Name of a defaulted register, {{org}} is replaced by the organization name.")
+	startCmd.Flags().StringVarP(&clusterOrgNamespace, "cluster-organization-namespace", "", "", "Designated namespace whose organizations govern the deployments of all namespaces, in place of cluster-scoped organizations; organizations stay namespaced and those in other namespaces govern no deployments. By default an organization only governs the deployments in its own namespace.")
+	startCmd.Flags().IntVarP(&requeueOptions.FastCount, "requeue-fast-count", "", requeue.DefaultFastCount, "Number of fast requeues of a resource after it changed.")
+	startCmd.Flags().DurationVarP(&requeueOptions.FastInterval, "requeue-fast-interval", "", requeue.DefaultFastInterval, "Requeue interval of a resource after it changed.")
+	startCmd.Flags().DurationVarP(&requeueOptions.SteadyInterval, "requeue-steady-interval", "", requeue.DefaultSteadyInterval, "Requeue interval of a resource after its fast requeues.")
+	startCmd.Flags().Float64VarP(&requeueOptions.Jitter, "requeue-jitter", "", requeue.DefaultJitter, "Maximum fraction of the requeue interval added to spread out the requeues.")
+	startCmd.Flags().StringVarP(&tracingOptions.Exporter, "tracing-exporter", "", tracing.ExporterNone, "Exporter of the trace spans: none, otlp or stdout.")
+	startCmd.Flags().StringVarP(&tracingOptions.Endpoint, "tracing-endpoint", "", tracing.DefaultEndpoint, "Address of the OTLP grpc collector the otlp exporter sends spans to.")
+	startCmd.Flags().BoolVarP(&tracingOptions.Insecure, "tracing-insecure", "", false, "Connect to the OTLP collector without TLS.")
+	startCmd.Flags().BoolVarP(&registryReadiness, "registry-readiness", "", true, "Report ready only when the backend of every register kind responds to a health check; a pod that is not ready also stops serving the webhooks. Disable with --registry-readiness=false when the backends are deployed after the organization package.")
+	startCmd.Flags().DurationVarP(&registryProbeInterval, "registry-probe-interval", "", registry.DefaultProbeInterval, "Interval of the registry backend health checks used by the registry readiness.")
+	startCmd.Flags().StringVarP(&backendNamespace, "registry-backend-namespace", "", pkgmetav1.Namespace, "Namespace of the registry backend services; requires rbac to list and watch services and discovery.k8s.io endpointslices in this namespace.")
+	startCmd.Flags().StringToStringVarP(&backendPackages, "registry-backend-packages", "", registry.DefaultBackendPackages(), "Package serving each register kind, as kind=package; the backend services are selected by the package label.")
+	startCmd.Flags().StringVarP(&registryCredentials.CASecretName, "registry-ca-secret", "", "", "Secret with the ca.crt that verifies the registry backends.")
+	startCmd.Flags().StringVarP(&registryCredentials.TLSSecretName, "registry-tls-secret", "", "", "TLS secret with the client certificate and key used to connect to the registry backends.")
+	startCmd.Flags().StringVarP(&registryCredentials.CredentialsSecretName, "registry-credentials-secret", "", "", "Basic-auth secret with the username and password used to connect to the registry backends.")
+	startCmd.Flags().StringVarP(&registryCredentials.ServerName, "registry-tls-server-name", "", "", "Name used to verify the certificates of the registry backends.")
+	startCmd.Flags().BoolVarP(&registryCredentials.SkipVerify, "registry-skip-verify", "", false, "Skip the verification of the certificates of the registry backends.")
+	startCmd.Flags().BoolVarP(&registryCredentials.Insecure, "registry-insecure", "", false, "Connect to the registry backends without TLS.")
 }
 
 func nddCtlrOptions(c int) controller.Options {
@@ -127,6 +285,33 @@ func nddCtlrOptions(c int) controller.Options {
 	}
 }
 
+// provisionWebhooks provisions the webhook service, certificate and
+// configurations and adds the controller that keeps the ca injected.
+func provisionWebhooks(mgr ctrl.Manager, log logging.Logger) error {
+	p, err := webhooks.NewProvisioner(mgr, log, webhooks.ProvisionOptions{
+		Namespace:   namespace,
+		PodName:     podname,
+		ServiceName: webhookServiceName,
+		Port:        webhookPort,
+		CertDir:     webhookCertDir,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Cannot create webhook provisioner")
+	}
+	if err := p.Provision(context.Background()); err != nil {
+		return err
+	}
+	return errors.Wrap(p.SetupWithManager(mgr), "Cannot add webhook provisioner to manager")
+}
+
+// getRegistryCredentials returns the registry credentials of the flags, with
+// the secrets in the namespace of the manager.
+func getRegistryCredentials() *registry.Credentials {
+	c := registryCredentials
+	c.Namespace = namespace
+	return &c
+}
+
 func getGnmiServerAddress(podname string) string {
 	//revision := strings.Split(podname, "-")[len(strings.Split(podname, "-"))-3]
 	var newName string
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/manifests"
+	"github.com/yndd/nddr-organization/internal/validation"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+var (
+	validateOutput         string
+	validateFailOnWarnings bool
+)
+
+// finding is a validation error of an object in the manifests
+type finding struct {
+	manifests.Source `json:",inline"`
+	Kind             string `json:"kind"`
+	Namespace        string `json:"namespace"`
+	Name             string `json:"name"`
+	Field            string `json:"field"`
+	Severity         string `json:"severity"`
+	Type             string `json:"type"`
+	Message          string `json:"message"`
+}
+
+// validateCmd validates the organization and deployment manifests
+var validateCmd = &cobra.Command{
+	Use:   "validate [directory|file]...",
+	Short: "validate organization and deployment manifests",
+	Long: "validate the organizations and deployments in the manifests with the rules of the admission webhooks; " +
+		"the command exits non-zero when it finds errors, and on warnings, i.e. missing critical registers, with --fail-on-warnings",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			args = []string{"."}
+		}
+		m, err := manifests.Load(args...)
+		if err != nil {
+			return errors.Wrap(err, "cannot load manifests")
+		}
+		findings := validateManifests(m, &defaults.Options{
+			RegisterKinds:        registerKinds,
+			RegisterNameTemplate: registerNameTemplate,
+		})
+		if err := printFindings(cmd.OutOrStdout(), validateOutput, findings); err != nil {
+			return err
+		}
+		if n := countFindings(findings, severityError); n > 0 {
+			return fmt.Errorf("%d validation errors found", n)
+		}
+		if n := countFindings(findings, severityWarning); n > 0 && validateFailOnWarnings {
+			return fmt.Errorf("%d validation warnings found", n)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVarP(&validateOutput, "output", "o", outputJSON, "Output format: json or yaml.")
+	validateCmd.Flags().BoolVarP(&validateFailOnWarnings, "fail-on-warnings", "", false, "Exit non-zero when warnings are found, e.g. the critical registers the controller would report missing.")
+	validateCmd.Flags().StringSliceVarP(&registerKinds, "default-register-kinds", "", defaults.DefaultRegisterKinds, "Register kinds added to an organization when missing.")
+	validateCmd.Flags().StringVarP(&registerNameTemplate, "default-register-name-template", "", defaults.DefaultRegisterNameTemplate, "Name of a defaulted register, {{org}} is replaced by the organization name.")
+	validateCmd.Flags().StringVarP(&clusterOrgNamespace, "cluster-organization-namespace", "", "", "Designated namespace whose organizations govern the deployments of all namespaces, in place of cluster-scoped organizations; organizations stay namespaced and those in other namespaces govern no deployments. By default an organization only governs the deployments in its own namespace.")
+}
+
+// validateManifests validates the manifests as the admission webhooks do;
+// the manifests are defaulted before they are validated, as by the API
+// server and the defaulting webhook. The warnings of an object are
+// only reported when it has no errors, as they are by the webhooks.
+func validateManifests(m *manifests.Manifests, o *defaults.Options) []*finding {
+	findings := make([]*finding, 0)
+	valid := make(map[*manifests.Organization]bool)
+	defaultManifestsSchema(m)
+	for _, org := range m.Organizations {
+		defaults.DefaultOrganization(org.Organization, o)
+		errs, warnings := validation.ValidateOrganizationAdmission(org.Organization)
+		findings = append(findings, newFindings(org.Source, orgv1alpha2.OrganizationKindKind, org.GetNamespace(), org.GetName(), severityError, errs)...)
+		if len(errs) > 0 {
+			continue
+		}
+		valid[org] = true
+		findings = append(findings, newFindings(org.Source, orgv1alpha2.OrganizationKindKind, org.GetNamespace(), org.GetName(), severityWarning, warnings)...)
+	}
+
+	for _, dep := range m.Deployments {
+		var org *orgv1alpha2.Organization
+		if mo := m.Organization(organizationNamespace(dep.GetNamespace()), dep.GetOrganizationName()); mo != nil {
+			if !valid[mo] {
+				// the organization is reported by itself
+				continue
+			}
+			org = mo.Organization
+		}
+		errs, warnings := validation.ValidateDeploymentAdmission(dep.Deployment, org, o)
+		findings = append(findings, newFindings(dep.Source, orgv1alpha2.DeploymentKindKind, dep.GetNamespace(), dep.GetName(), severityError, errs)...)
+		if len(errs) > 0 {
+			continue
+		}
+		findings = append(findings, newFindings(dep.Source, orgv1alpha2.DeploymentKindKind, dep.GetNamespace(), dep.GetName(), severityWarning, warnings)...)
+	}
+	return findings
+}
+
+func newFindings(src manifests.Source, kind, namespace, name, severity string, errs field.ErrorList) []*finding {
+	findings := make([]*finding, 0, len(errs))
+	for _, err := range errs {
+		findings = append(findings, &finding{
+			Source:    src,
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+			Severity:  severity,
+			Field:     err.Field,
+			Type:      string(err.Type),
+			Message:   err.ErrorBody(),
+		})
+	}
+	return findings
+}
+
+func countFindings(findings []*finding, severity string) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func printFindings(w io.Writer, output string, findings []*finding) error {
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(findings)
+	case outputYAML:
+		b, err := yaml.Marshal(findings)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	return fmt.Errorf("unknown output format %s, use json or yaml", output)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intent
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/manifests"
+	"github.com/yndd/nddr-organization/internal/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func register(kind, name string) *nddov1.Register {
+	return &nddov1.Register{Kind: utils.StringPtr(kind), Name: utils.StringPtr(name)}
+}
+
+// admissionFindings returns the findings of the admission webhooks for the
+// organization and deployment; the objects are defaulted as by the API server
+// and the defaulting webhooks, and the deployment is only admitted with an
+// admitted organization.
+func admissionFindings(org *orgv1alpha2.Organization, dep *orgv1alpha2.Deployment, o *defaults.Options) []string {
+	org, dep = org.DeepCopy(), dep.DeepCopy()
+	f := make([]string, 0)
+	add := func(kind, severity string, errs field.ErrorList) {
+		for _, err := range errs {
+			f = append(f, fmt.Sprintf("%s %s %s %s", kind, severity, string(err.Type), err.Field))
+		}
+	}
+
+	defaults.DefaultOrganizationSchema(org)
+	defaults.DefaultOrganization(org, o)
+	errs, warnings := validation.ValidateOrganizationAdmission(org)
+	add(orgv1alpha2.OrganizationKindKind, severityError, errs)
+	add(orgv1alpha2.OrganizationKindKind, severityWarning, warnings)
+	if len(errs) > 0 {
+		return f
+	}
+
+	defaults.DefaultDeploymentSchema(dep)
+	errs, warnings = validation.ValidateDeploymentAdmission(dep, org, o)
+	add(orgv1alpha2.DeploymentKindKind, severityError, errs)
+	add(orgv1alpha2.DeploymentKindKind, severityWarning, warnings)
+	return f
+}
+
+// cliFindings returns the findings of the validate command for the
+// organization and deployment.
+func cliFindings(org *orgv1alpha2.Organization, dep *orgv1alpha2.Deployment, o *defaults.Options) []string {
+	m := &manifests.Manifests{
+		Organizations: []*manifests.Organization{{Organization: org.DeepCopy()}},
+		Deployments:   []*manifests.Deployment{{Deployment: dep.DeepCopy()}},
+	}
+	f := make([]string, 0)
+	for _, finding := range validateManifests(m, o) {
+		f = append(f, fmt.Sprintf("%s %s %s %s", finding.Kind, finding.Severity, finding.Type, finding.Field))
+	}
+	return f
+}
+
+func TestValidateManifestsMatchesAdmission(t *testing.T) {
+	o := &defaults.Options{RegisterNameTemplate: defaults.DefaultRegisterNameTemplate}
+	complete := []*nddov1.Register{register("ipam", "nokia.default"), register("as", "nokia.default")}
+	org := func(name string, spec *orgv1alpha2.OrgOrganization) *orgv1alpha2.Organization {
+		return &orgv1alpha2.Organization{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       orgv1alpha2.OrganizationSpec{Organization: spec},
+		}
+	}
+	dep := func(name string, spec *orgv1alpha2.OrgDeployment) *orgv1alpha2.Deployment {
+		return &orgv1alpha2.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       orgv1alpha2.DeploymentSpec{Deployment: spec},
+		}
+	}
+	validOrg := org("nokia", &orgv1alpha2.OrgOrganization{Register: complete})
+	validDep := dep("nokia.region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia")})
+
+	cases := map[string]struct {
+		org  *orgv1alpha2.Organization
+		dep  *orgv1alpha2.Deployment
+		want []string
+	}{
+		"Valid": {
+			org: validOrg,
+			dep: validDep,
+		},
+		"OrganizationNameWithDot": {
+			org:  org("nokia.be", &orgv1alpha2.OrgOrganization{Register: complete}),
+			dep:  dep("nokia.be.region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia.be")}),
+			want: []string{"Organization error FieldValueInvalid metadata.name"},
+		},
+		"OrganizationAdminState": {
+			org:  org("nokia", &orgv1alpha2.OrgOrganization{AdminState: utils.StringPtr("up"), Register: complete}),
+			dep:  validDep,
+			want: []string{"Organization error FieldValueNotSupported spec.organization.admin-state"},
+		},
+		"OrganizationDeletionPolicy": {
+			org:  org("nokia", &orgv1alpha2.OrgOrganization{DeletionPolicy: utils.StringPtr("orphan"), Register: complete}),
+			dep:  validDep,
+			want: []string{"Organization error FieldValueNotSupported spec.organization.deletion-policy"},
+		},
+		"OrganizationRegister": {
+			org: org("nokia", &orgv1alpha2.OrgOrganization{Register: append(complete[:2:2],
+				register("unknown", "nokia.default"), &nddov1.Register{Kind: utils.StringPtr("vlan")})}),
+			dep: validDep,
+			want: []string{
+				"Organization error FieldValueNotSupported spec.organization.register[2].kind",
+				"Organization error FieldValueRequired spec.organization.register[3].name",
+			},
+		},
+		"OrganizationRequiredRegisters": {
+			org:  org("nokia", &orgv1alpha2.OrgOrganization{Register: complete, RequiredRegisters: []string{"unknown"}}),
+			dep:  validDep,
+			want: []string{"Organization error FieldValueNotSupported spec.organization.required-registers[0]"},
+		},
+		"OrganizationDeploymentRequiredRegisters": {
+			org: org("nokia", &orgv1alpha2.OrgOrganization{Register: complete, DeploymentRequiredRegisters: []*orgv1alpha2.OrgDeploymentRequiredRegisters{
+				{DeploymentKind: utils.StringPtr("dc")},
+				{DeploymentKind: utils.StringPtr("dc")},
+			}}),
+			dep:  validDep,
+			want: []string{"Organization error FieldValueDuplicate spec.organization.deployment-required-registers[1].deployment-kind"},
+		},
+		"OrganizationCriticalRegisters": {
+			org: org("nokia", &orgv1alpha2.OrgOrganization{Register: complete[:1]}),
+			dep: validDep,
+			want: []string{
+				"Organization warning FieldValueRequired spec.organization.register[as]",
+				"Deployment warning FieldValueRequired spec.deployment.register[as]",
+			},
+		},
+		"DeploymentName": {
+			org:  validOrg,
+			dep:  dep("region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia")}),
+			want: []string{"Deployment error FieldValueInvalid metadata.name"},
+		},
+		"DeploymentOrganizationRef": {
+			org:  validOrg,
+			dep:  dep("nokia.region1", &orgv1alpha2.OrgDeployment{}),
+			want: []string{"Deployment error FieldValueRequired spec.deployment.organization-ref"},
+		},
+		"DeploymentKind": {
+			org:  validOrg,
+			dep:  dep("nokia.region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia"), Kind: utils.StringPtr("lan")}),
+			want: []string{"Deployment error FieldValueNotSupported spec.deployment.kind"},
+		},
+		"DeploymentRegister": {
+			org: validOrg,
+			dep: dep("nokia.region1", &orgv1alpha2.OrgDeployment{
+				OrganizationRef: utils.StringPtr("nokia"),
+				Register:        []*nddov1.Register{register("unknown", "nokia.region1")},
+			}),
+			want: []string{"Deployment error FieldValueNotSupported spec.deployment.register[0].kind"},
+		},
+		"DeploymentCriticalRegisters": {
+			org: org("nokia", &orgv1alpha2.OrgOrganization{Register: complete, DeploymentRequiredRegisters: []*orgv1alpha2.OrgDeploymentRequiredRegisters{
+				{DeploymentKind: utils.StringPtr("wan"), RequiredRegisters: []string{"ipam", "vlan"}},
+			}}),
+			dep:  dep("nokia.region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia"), Kind: utils.StringPtr("wan")}),
+			want: []string{"Deployment warning FieldValueRequired spec.deployment.register[vlan]"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			admission := admissionFindings(tc.org, tc.dep, o)
+			if diff := cmp.Diff(tc.want, admission, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("admission: -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(admission, cliFindings(tc.org, tc.dep, o), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("validateManifests(...): -admission, +got:\n%s", diff)
+			}
+		})
+	}
+}
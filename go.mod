@@ -3,15 +3,26 @@ module github.com/yndd/nddr-organization
 go 1.16
 
 require (
+	github.com/google/go-cmp v0.5.6
 	github.com/karimra/gnmic v0.20.4 // indirect
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.11.0
 	github.com/spf13/cobra v1.2.1
 	github.com/yndd/ndd-core v0.1.6
 	github.com/yndd/ndd-runtime v0.1.6
 	github.com/yndd/nddo-grpc v0.0.11
 	github.com/yndd/nddo-runtime v0.0.18
+	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.27.0
+	go.opentelemetry.io/otel v1.2.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.2.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.2.0
+	go.opentelemetry.io/otel/sdk v1.2.0
+	go.opentelemetry.io/otel/trace v1.2.0
+	google.golang.org/grpc v1.42.0
+	google.golang.org/protobuf v1.27.1
 	k8s.io/api v0.22.2
 	k8s.io/apimachinery v0.22.2
 	k8s.io/client-go v0.22.2
 	sigs.k8s.io/controller-runtime v0.9.3
+	sigs.k8s.io/yaml v1.2.0
 )
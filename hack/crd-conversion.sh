@@ -0,0 +1,41 @@
+#!/usr/bin/env bash
+# Copyright 2021 NDD.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# crd-conversion.sh adds the conversion webhook stanza to the generated crds.
+# The service namespace and the caBundle are set by the manager when it
+# provisions the webhooks at start, see internal/webhooks/provision.go.
+
+set -euo pipefail
+
+for crd in "$@"; do
+	awk '
+		{ print }
+		$0 == "spec:" && !done {
+			print "  conversion:"
+			print "    strategy: Webhook"
+			print "    webhook:"
+			print "      clientConfig:"
+			print "        service:"
+			print "          name: nddr-organization-webhook"
+			print "          namespace: ndd-system"
+			print "          path: /convert"
+			print "      conversionReviewVersions:"
+			print "      - v1"
+			print "      - v1beta1"
+			done = 1
+		}
+	' "${crd}" > "${crd}.tmp"
+	mv "${crd}.tmp" "${crd}"
+done
@@ -17,16 +17,27 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+
 	"github.com/yndd/nddr-organization/internal/controllers/deployment2"
 	"github.com/yndd/nddr-organization/internal/controllers/organization"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
+	"github.com/yndd/nddr-organization/internal/index"
+	"github.com/yndd/nddr-organization/internal/metrics"
 	"github.com/yndd/nddr-organization/internal/shared"
 )
 
 // Setup package controllers.
 func Setup(mgr ctrl.Manager, option controller.Options, nddcopts *shared.NddControllerOptions) error {
+	if err := index.Setup(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	if err := metrics.Setup(mgr.GetClient()); err != nil {
+		return err
+	}
+
 	for _, setup := range []func(ctrl.Manager, controller.Options, *shared.NddControllerOptions) error{
 		organization.Setup,
 		deployment2.Setup,
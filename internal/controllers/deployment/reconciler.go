@@ -32,7 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	orgv1alpha1 "github.com/yndd/nddr-organization/apis/org/v1alpha1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
 	"github.com/yndd/nddr-organization/internal/shared"
 )
 
@@ -69,8 +69,8 @@ type Reconciler struct {
 	record  event.Recorder
 	managed mrManaged
 
-	newDeployment       func() orgv1alpha1.Dp
-	newOrganizationList func() orgv1alpha1.OrgList
+	newDeployment       func() orgv1alpha2.Dp
+	newOrganizationList func() orgv1alpha2.OrgList
 }
 
 type mrManaged struct {
@@ -84,13 +84,13 @@ func WithLogger(log logging.Logger) ReconcilerOption {
 	}
 }
 
-func WithNewReourceFn(f func() orgv1alpha1.Dp) ReconcilerOption {
+func WithNewReourceFn(f func() orgv1alpha2.Dp) ReconcilerOption {
 	return func(r *Reconciler) {
 		r.newDeployment = f
 	}
 }
 
-func WithNewOrganizationListFn(f func() orgv1alpha1.OrgList) ReconcilerOption {
+func WithNewOrganizationListFn(f func() orgv1alpha2.OrgList) ReconcilerOption {
 	return func(r *Reconciler) {
 		r.newOrganizationList = f
 	}
@@ -111,9 +111,9 @@ func defaultMRManaged(m ctrl.Manager) mrManaged {
 
 // Setup adds a controller that reconciles Topology.
 func Setup(mgr ctrl.Manager, o controller.Options, nddcopts *shared.NddControllerOptions) error {
-	name := "nddr/" + strings.ToLower(orgv1alpha1.DeploymentGroupKind)
-	fn := func() orgv1alpha1.Dp { return &orgv1alpha1.Deployment{} }
-	orglfn := func() orgv1alpha1.OrgList { return &orgv1alpha1.OrganizationList{} }
+	name := "nddr/" + strings.ToLower(orgv1alpha2.DeploymentGroupKind)
+	fn := func() orgv1alpha2.Dp { return &orgv1alpha2.Deployment{} }
+	orglfn := func() orgv1alpha2.OrgList { return &orgv1alpha2.OrganizationList{} }
 
 	r := NewReconciler(mgr,
 		WithLogger(nddcopts.Logger.WithValues("controller", name)),
@@ -131,9 +131,9 @@ func Setup(mgr ctrl.Manager, o controller.Options, nddcopts *shared.NddControlle
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o).
-		For(&orgv1alpha1.Deployment{}).
+		For(&orgv1alpha2.Deployment{}).
 		WithEventFilter(resource.IgnoreUpdateWithoutGenerationChangePredicate()).
-		Watches(&source.Kind{Type: &orgv1alpha1.Organization{}}, orgHandler).
+		Watches(&source.Kind{Type: &orgv1alpha2.Organization{}}, orgHandler).
 		Complete(r)
 }
 
@@ -184,7 +184,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			// backoff.
 			record.Event(cr, event.Warning(reasonCannotDeleteFInalizer, err))
 			log.Debug("Cannot remove managed resource finalizer", "error", err)
-			cr.SetConditions(nddv1.ReconcileError(err), orgv1alpha1.NotReady())
+			cr.SetConditions(nddv1.ReconcileError(err), orgv1alpha2.NotReady())
 			return reconcile.Result{Requeue: true}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
 		}
 
@@ -202,40 +202,42 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		// not, we requeue explicitly, which will trigger backoff.
 		record.Event(cr, event.Warning(reasonCannotAddFInalizer, err))
 		log.Debug("Cannot add finalizer", "error", err)
-		cr.SetConditions(nddv1.ReconcileError(err), orgv1alpha1.NotReady())
+		cr.SetConditions(nddv1.ReconcileError(err), orgv1alpha2.NotReady())
 		return reconcile.Result{Requeue: true}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	if err := cr.InitializeResource(); err != nil {
 		record.Event(cr, event.Warning(reasonCannotInitialize, err))
 		log.Debug("Cannot initialize", "error", err)
-		cr.SetConditions(nddv1.ReconcileError(err), orgv1alpha1.NotReady())
+		cr.SetConditions(nddv1.ReconcileError(err), orgv1alpha2.NotReady())
 		return reconcile.Result{Requeue: true}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
 	if err := r.handleAppLogic(ctx, cr); err != nil {
 		record.Event(cr, event.Warning(reasonAppLogicFailed, err))
 		log.Debug("handle applogic failed", "error", err)
-		cr.SetConditions(nddv1.ReconcileError(err), orgv1alpha1.NotReady())
+		cr.SetConditions(nddv1.ReconcileError(err), orgv1alpha2.NotReady())
 		return reconcile.Result{Requeue: true}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
-	cr.SetConditions(nddv1.ReconcileSuccess(), orgv1alpha1.Ready())
+	cr.SetConditions(nddv1.ReconcileSuccess(), orgv1alpha2.Ready())
 	// we don't need to requeue for topology
 	return reconcile.Result{}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
 }
 
-func (r *Reconciler) handleAppLogic(ctx context.Context, cr orgv1alpha1.Dp) error {
+func (r *Reconciler) handleAppLogic(ctx context.Context, cr orgv1alpha2.Dp) error {
 	orgs := r.newOrganizationList()
 	if err := r.client.List(ctx, orgs); err != nil {
 		return err
 	}
 
 	orgfound := false
+	orgAdminState := ""
 	orgregister := make(map[string]string)
 	for _, org := range orgs.GetOrganizations() {
 		if org.GetOrganizationName() == cr.GetOrganizationName() {
 			orgfound = true
+			orgAdminState = org.GetAdminState()
 			orgregister = org.GetRegister()
 			break
 		}
@@ -247,7 +249,11 @@ func (r *Reconciler) handleAppLogic(ctx context.Context, cr orgv1alpha1.Dp) erro
 		return errors.New("organization not found")
 	}
 
-	if cr.GetAdminState() == "disable" {
+	if orgAdminState == "disable" {
+		cr.SetStatus("down")
+		cr.SetReason("organization admin state disabled")
+		cr.SetStateRegister(make(map[string]string))
+	} else if cr.GetAdminState() == "disable" {
 		cr.SetStatus("down")
 		cr.SetReason("admin state disabled")
 		cr.SetStateRegister(make(map[string]string))
@@ -21,7 +21,7 @@ import (
 
 	//ndddvrv1 "github.com/yndd/ndd-core/apis/dvr/v1"
 	"github.com/yndd/ndd-runtime/pkg/logging"
-	orgv1alpha1 "github.com/yndd/nddr-organization/apis/org/v1alpha1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -62,14 +62,14 @@ func (e *EnqueueRequestForAllOrganizations) Generic(evt event.GenericEvent, q wo
 }
 
 func (e *EnqueueRequestForAllOrganizations) add(obj runtime.Object, queue adder) {
-	dd, ok := obj.(*orgv1alpha1.Organization)
+	dd, ok := obj.(*orgv1alpha2.Organization)
 	if !ok {
 		return
 	}
 	log := e.log.WithValues("function", "watch org", "name", dd.GetName())
 	log.Debug("handleEvent")
 
-	d := &orgv1alpha1.DeploymentList{}
+	d := &orgv1alpha2.DeploymentList{}
 	if err := e.client.List(e.ctx, d); err != nil {
 		return
 	}
@@ -20,92 +20,117 @@ import (
 	"context"
 	"errors"
 	"strings"
-	"sync"
 	"time"
 
+	nddv1 "github.com/yndd/ndd-runtime/apis/common/v1"
 	"github.com/yndd/ndd-runtime/pkg/event"
 	"github.com/yndd/ndd-runtime/pkg/logging"
-	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
 	"github.com/yndd/nddo-runtime/pkg/reconciler/managed"
 	"github.com/yndd/nddo-runtime/pkg/resource"
-	orgv1alpha1 "github.com/yndd/nddr-organization/apis/org/v1alpha1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/metrics"
+	"github.com/yndd/nddr-organization/internal/requeue"
+	"github.com/yndd/nddr-organization/internal/resolve"
 	"github.com/yndd/nddr-organization/internal/shared"
+	"github.com/yndd/nddr-organization/internal/status"
+	"github.com/yndd/nddr-organization/internal/tracing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
-	// timers
-	reconcileTimeout = 1 * time.Minute
-	veryShortWait    = 1 * time.Second
 	// errors
 	errUnexpectedResource = "unexpected deployment object"
 	errGetK8sResource     = "cannot get deployment resource"
 )
 
+// conditionReasons maps the reason a deployment is down to the reason of its
+// RequiredRegisters condition.
+var conditionReasons = map[string]nddv1.ConditionReason{
+	resolve.ReasonOrganizationNotFound:      orgv1alpha2.ConditionReasonOrganizationNotFound,
+	resolve.ReasonOrganizationAdminDisabled: orgv1alpha2.ConditionReasonOrganizationAdminDisabled,
+	resolve.ReasonAdminDisabled:             orgv1alpha2.ConditionReasonAdminDisabled,
+}
+
 // Setup adds a controller that reconciles infra.
 func Setup(mgr ctrl.Manager, o controller.Options, nddcopts *shared.NddControllerOptions) error {
-	name := "nddo/" + strings.ToLower(orgv1alpha1.DeploymentGroupKind)
-	depfn := func() orgv1alpha1.Dp { return &orgv1alpha1.Deployment{} }
-	deplfn := func() orgv1alpha1.DpList { return &orgv1alpha1.DeploymentList{} }
-	orglfn := func() orgv1alpha1.OrgList { return &orgv1alpha1.OrganizationList{} }
+	name := "nddo/" + strings.ToLower(orgv1alpha2.DeploymentGroupKind)
+	depfn := func() orgv1alpha2.Dp { return &orgv1alpha2.Deployment{} }
+	deplfn := func() orgv1alpha2.DpList { return &orgv1alpha2.DeploymentList{} }
+	orgfn := func() orgv1alpha2.Org { return &orgv1alpha2.Organization{} }
 
-	speedy := make(map[string]int)
+	policy := requeue.New(nddcopts.Requeue)
 
-	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(orgv1alpha1.DeploymentGroupVersionKind),
+	r := managed.NewReconciler(status.NewManager(mgr, name),
+		resource.ManagedKind(orgv1alpha2.DeploymentGroupVersionKind),
 		managed.WithLogger(nddcopts.Logger.WithValues("controller", name)),
 		managed.WithApplication(&application{
 			client: resource.ClientApplicator{
 				Client:     mgr.GetClient(),
 				Applicator: resource.NewAPIPatchingApplicator(mgr.GetClient()),
 			},
-			log:        nddcopts.Logger.WithValues("applogic", name),
-			newDep:     depfn,
-			newOrgList: orglfn,
-			speedy:     speedy,
+			log:          nddcopts.Logger.WithValues("applogic", name),
+			controller:   name,
+			newDep:       depfn,
+			newOrg:       orgfn,
+			orgNamespace: nddcopts.OrganizationNamespace,
+			defaults: &defaults.Options{
+				RegisterKinds:        nddcopts.RegisterKinds,
+				RegisterNameTemplate: nddcopts.RegisterNameTemplate,
+			},
+			requeue: policy,
 		}),
 		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
 	)
 
 	orgHandler := &EnqueueRequestForAllOrganizations{
-		client:     mgr.GetClient(),
-		log:        nddcopts.Logger,
-		ctx:        context.Background(),
-		newDepList: deplfn,
-		speedy:     speedy,
+		client:       mgr.GetClient(),
+		log:          nddcopts.Logger,
+		ctx:          context.Background(),
+		newDepList:   deplfn,
+		orgNamespace: nddcopts.OrganizationNamespace,
+		depNamespace: nddcopts.DeploymentNamespace,
+		requeue:      policy,
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o).
-		For(&orgv1alpha1.Deployment{}).
-		Owns(&orgv1alpha1.Deployment{}).
+		For(&orgv1alpha2.Deployment{}, builder.WithPredicates(policy.ResetOnGenerationChange())).
+		Owns(&orgv1alpha2.Deployment{}).
 		WithEventFilter(resource.IgnoreUpdateWithoutGenerationChangePredicate()).
-		Watches(&source.Kind{Type: &orgv1alpha1.Organization{}}, orgHandler).
-		Complete(r)
+		Watches(&source.Kind{Type: &orgv1alpha2.Organization{}}, orgHandler).
+		Complete(policy.Reconciler(r))
 
 }
 
 type application struct {
-	client resource.ClientApplicator
-	log    logging.Logger
+	client     resource.ClientApplicator
+	log        logging.Logger
+	controller string
+
+	newDep func() orgv1alpha2.Dp
+	newOrg func() orgv1alpha2.Org
 
-	newDep     func() orgv1alpha1.Dp
-	newOrgList func() orgv1alpha1.OrgList
+	// orgNamespace returns the namespace of the organization of a deployment
+	orgNamespace func(string) string
 
-	speedy map[string]int
+	defaults *defaults.Options
 
-	speedyMutex sync.Mutex
+	requeue *requeue.Policy
 }
 
-func getCrName(cr orgv1alpha1.Dp) string {
-	return strings.Join([]string{cr.GetNamespace(), cr.GetName()}, ".")
+func getCrName(cr orgv1alpha2.Dp) string {
+	return requeue.Key(cr.GetNamespace(), cr.GetName())
 }
 
 func (r *application) Initialize(ctx context.Context, mg resource.Managed) error {
-	cr, ok := mg.(*orgv1alpha1.Deployment)
+	cr, ok := mg.(*orgv1alpha2.Deployment)
 	if !ok {
 		return errors.New(errUnexpectedResource)
 	}
@@ -119,29 +144,25 @@ func (r *application) Initialize(ctx context.Context, mg resource.Managed) error
 }
 
 func (r *application) Update(ctx context.Context, mg resource.Managed) (map[string]string, error) {
-	cr, ok := mg.(*orgv1alpha1.Deployment)
+	cr, ok := mg.(*orgv1alpha2.Deployment)
 	if !ok {
 		return nil, errors.New(errUnexpectedResource)
 	}
 
-	return r.handleAppLogic(ctx, cr)
+	ctx, span := tracing.Start(ctx, "deployment.handleAppLogic", cr)
+	info, err := r.handleAppLogic(ctx, cr)
+	tracing.End(span, err)
+	return info, err
 }
 
 func (r *application) FinalUpdate(ctx context.Context, mg resource.Managed) {
+	cr, _ := mg.(*orgv1alpha2.Deployment)
+	r.requeue.Next(getCrName(cr))
 }
 
 func (r *application) Timeout(ctx context.Context, mg resource.Managed) time.Duration {
-	cr, _ := mg.(*orgv1alpha1.Deployment)
-	crName := getCrName(cr)
-	r.speedyMutex.Lock()
-	speedy := r.speedy[crName]
-	r.speedyMutex.Unlock()
-	if speedy <= 5 {
-		r.log.Debug("Speedy", "number", speedy)
-		speedy++
-		return veryShortWait
-	}
-	return reconcileTimeout
+	cr, _ := mg.(*orgv1alpha2.Deployment)
+	return r.requeue.Next(getCrName(cr))
 }
 
 func (r *application) Delete(ctx context.Context, mg resource.Managed) (bool, error) {
@@ -149,67 +170,51 @@ func (r *application) Delete(ctx context.Context, mg resource.Managed) (bool, er
 }
 
 func (r *application) FinalDelete(ctx context.Context, mg resource.Managed) {
-	cr, _ := mg.(*orgv1alpha1.Deployment)
-	crName := getCrName(cr)
-	r.speedyMutex.Lock()
-	delete(r.speedy, crName)
-	r.speedyMutex.Unlock()
+	cr, _ := mg.(*orgv1alpha2.Deployment)
+	r.requeue.Forget(getCrName(cr))
 }
 
-func (r *application) handleAppLogic(ctx context.Context, cr orgv1alpha1.Dp) (map[string]string, error) {
+func (r *application) handleAppLogic(ctx context.Context, cr orgv1alpha2.Dp) (map[string]string, error) {
 	log := r.log.WithValues("function", "handleAppLogic", "crname", cr.GetName())
 	log.Debug("handleAppLogic")
 
-	orgs := r.newOrgList()
-	if err := r.client.List(ctx, orgs); err != nil {
-		return nil, err
+	org := r.newOrg()
+	if err := r.client.Get(ctx, types.NamespacedName{
+		Namespace: r.orgNamespace(cr.GetNamespace()),
+		Name:      cr.GetOrganizationName(),
+	}, org); err != nil {
+		if !apierrors.IsNotFound(err) {
+			metrics.AppLogicFailed(r.controller, metrics.ReasonError)
+			return nil, err
+		}
+		org = nil
 	}
 
-	orgfound := false
-	orgRegister := make(map[string]string)
-	var orgAddressAllocationStrategy *nddov1.AddressAllocationStrategy
-	for _, org := range orgs.GetOrganizations() {
-		if org.GetOrganizationName() == cr.GetOrganizationName() {
-			orgfound = true
-			orgRegister = org.GetRegister()
-			orgAddressAllocationStrategy = org.GetAddressAllocationStrategy()
-			break
-		}
+	d := resolve.ResolveDeployment(cr, org, r.defaults)
+	if d.Status != resolve.StatusUp {
+		metrics.AppLogicFailed(r.controller, d.Reason)
 	}
-	if !orgfound {
-		cr.SetStatus("down")
-		cr.SetReason("organization not found")
-		cr.SetStateRegister(make(map[string]string))
-		return nil, errors.New("organization not found")
+	cr.SetStatus(d.Status)
+	cr.SetReason(d.Reason)
+	cr.SetStateRequiredRegisters(d.RequiredRegisters)
+	cr.SetStateRegisterWithSource(d.Register, d.RegisterSource)
+	cr.SetStateAddressAllocationStrategy(d.AddressAllocationStrategy)
+	cr.SetStateAddressAllocationStrategySource(d.AddressAllocationStrategySource)
+	switch {
+	case d.Status != resolve.StatusUp:
+		cr.SetConditions(orgv1alpha2.RequiredRegistersNotEvaluated(conditionReasons[d.Reason]))
+	case len(d.MissingRegisters) > 0:
+		cr.SetConditions(orgv1alpha2.RequiredRegistersMissing(d.MissingRegisters))
+	default:
+		cr.SetConditions(orgv1alpha2.RequiredRegistersPresent())
 	}
-
-	if cr.GetAdminState() == "disable" {
-		cr.SetStatus("down")
-		cr.SetReason("admin state disabled")
-		cr.SetStateRegister(make(map[string]string))
+	if len(d.Overrides) > 0 {
+		cr.SetConditions(orgv1alpha2.RegisterOverride(d.Overrides))
 	} else {
-		cr.SetStatus("up")
-		cr.SetReason("")
-		depRegister := getDeploymentRegister(orgRegister, cr.GetRegister())
-		cr.SetStateRegister(depRegister)
-		aas := getDeploymentAddresssAllocationStrategy(orgAddressAllocationStrategy, cr.GetAddressAllocationStrategy())
-		cr.SetStateAddressAllocationStrategy(aas)
+		cr.SetConditions(orgv1alpha2.NoRegisterOverride())
 	}
-	return make(map[string]string), nil
-}
-
-func getDeploymentRegister(orgRegister, depRegister map[string]string) map[string]string {
-	for orgKind, orgName := range orgRegister {
-		if _, ok := depRegister[orgKind]; !ok {
-			depRegister[orgKind] = orgName
-		}
+	if org == nil {
+		return nil, errors.New(d.Reason)
 	}
-	return depRegister
-}
-
-func getDeploymentAddresssAllocationStrategy(orgass, depaas *nddov1.AddressAllocationStrategy) *nddov1.AddressAllocationStrategy {
-	if depaas != nil {
-		return depaas
-	}
-	return orgass
+	return make(map[string]string), nil
 }
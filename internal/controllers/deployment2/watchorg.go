@@ -18,11 +18,13 @@ package deployment2
 
 import (
 	"context"
-	"sync"
 
 	//ndddvrv1 "github.com/yndd/ndd-core/apis/dvr/v1"
 	"github.com/yndd/ndd-runtime/pkg/logging"
-	orgv1alpha1 "github.com/yndd/nddr-organization/apis/org/v1alpha1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/index"
+	"github.com/yndd/nddr-organization/internal/metrics"
+	"github.com/yndd/nddr-organization/internal/requeue"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
@@ -40,59 +42,76 @@ type EnqueueRequestForAllOrganizations struct {
 	log    logging.Logger
 	ctx    context.Context
 
-	speedy map[string]int
-	mutex  sync.Mutex
+	requeue *requeue.Policy
 
-	newDepList func() orgv1alpha1.DpList
+	newDepList func() orgv1alpha2.DpList
+	// orgNamespace returns the namespace of the organization of a deployment
+	orgNamespace func(string) string
+	// depNamespace returns the namespace of the deployments of an organization
+	depNamespace func(string) string
 }
 
 // Create enqueues a request for all infrastructures which pertains to the topology.
 func (e *EnqueueRequestForAllOrganizations) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.Object, q)
+	e.add(q, evt.Object)
 }
 
 // Create enqueues a request for all infrastructures which pertains to the topology.
 func (e *EnqueueRequestForAllOrganizations) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.ObjectOld, q)
-	e.add(evt.ObjectNew, q)
+	e.add(q, evt.ObjectOld, evt.ObjectNew)
 }
 
 // Create enqueues a request for all infrastructures which pertains to the topology.
 func (e *EnqueueRequestForAllOrganizations) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.Object, q)
+	e.add(q, evt.Object)
 }
 
 // Create enqueues a request for all infrastructures which pertains to the topology.
 func (e *EnqueueRequestForAllOrganizations) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
-	e.add(evt.Object, q)
+	e.add(q, evt.Object)
 }
 
-func (e *EnqueueRequestForAllOrganizations) add(obj runtime.Object, queue adder) {
-	dd, ok := obj.(*orgv1alpha1.Organization)
+// add enqueues the deployments the supplied organizations govern and
+// observes the fan-out once for all of them.
+func (e *EnqueueRequestForAllOrganizations) add(queue adder, objs ...runtime.Object) {
+	requests := make(map[reconcile.Request]struct{})
+	for _, obj := range objs {
+		for _, req := range e.requests(obj) {
+			requests[req] = struct{}{}
+		}
+	}
+	for req := range requests {
+		queue.Add(req)
+	}
+	metrics.ObserveFanOut(len(requests))
+}
+
+func (e *EnqueueRequestForAllOrganizations) requests(obj runtime.Object) []reconcile.Request {
+	dd, ok := obj.(*orgv1alpha2.Organization)
 	if !ok {
-		return
+		return nil
 	}
 	log := e.log.WithValues("function", "watch org", "name", dd.GetName())
 	log.Debug("handleEvent")
 
 	d := e.newDepList()
-	if err := e.client.List(e.ctx, d); err != nil {
-		return
+	if err := e.client.List(e.ctx, d,
+		client.InNamespace(e.depNamespace(dd.GetNamespace())),
+		client.MatchingFields{index.DeploymentOrganization: dd.GetName()}); err != nil {
+		return nil
 	}
 
+	requests := make([]reconcile.Request, 0)
 	for _, dep := range d.GetDeployments() {
-		// only enqueue if the organization name match
-		if dep.GetOrganizationName() == dd.GetName() {
-
-			crName := getCrName(dep)
-
-			e.mutex.Lock()
-			e.speedy[crName] = 0
-			e.mutex.Unlock()
-
-			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
-				Namespace: dd.GetNamespace(),
-				Name:      dep.GetName()}})
+		// only enqueue the deployments the organization governs
+		if e.orgNamespace(dep.GetNamespace()) != dd.GetNamespace() {
+			continue
 		}
+		e.requeue.Reset(getCrName(dep))
+
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: dep.GetNamespace(),
+			Name:      dep.GetName()}})
 	}
+	return requests
 }
@@ -19,24 +19,32 @@ package organization
 import (
 	"context"
 	"errors"
+	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/yndd/ndd-runtime/pkg/event"
 	"github.com/yndd/ndd-runtime/pkg/logging"
 	"github.com/yndd/nddo-runtime/pkg/reconciler/managed"
 	"github.com/yndd/nddo-runtime/pkg/resource"
-	orgv1alpha1 "github.com/yndd/nddr-organization/apis/org/v1alpha1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/index"
+	"github.com/yndd/nddr-organization/internal/metrics"
+	"github.com/yndd/nddr-organization/internal/requeue"
+	"github.com/yndd/nddr-organization/internal/resolve"
 	"github.com/yndd/nddr-organization/internal/shared"
+	"github.com/yndd/nddr-organization/internal/status"
+	"github.com/yndd/nddr-organization/internal/tracing"
+	"github.com/yndd/nddr-organization/pkg/registry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
-	// timers
-	reconcileTimeout = 1 * time.Minute
-	veryShortWait    = 1 * time.Second
 	// errors
 	errUnexpectedResource = "unexpected organization object"
 	errGetK8sResource     = "cannot get organization resource"
@@ -44,55 +52,76 @@ const (
 
 // Setup adds a controller that reconciles infra.
 func Setup(mgr ctrl.Manager, o controller.Options, nddcopts *shared.NddControllerOptions) error {
-	name := "nddo/" + strings.ToLower(orgv1alpha1.OrganizationGroupKind)
-	orgfn := func() orgv1alpha1.Org { return &orgv1alpha1.Organization{} }
+	name := "nddo/" + strings.ToLower(orgv1alpha2.OrganizationGroupKind)
+	orgfn := func() orgv1alpha2.Org { return &orgv1alpha2.Organization{} }
+	deplfn := func() orgv1alpha2.DpList { return &orgv1alpha2.DeploymentList{} }
 
-	speedy := make(map[string]int)
+	policy := requeue.New(nddcopts.Requeue)
 
-	r := managed.NewReconciler(mgr,
-		resource.ManagedKind(orgv1alpha1.OrganizationGroupVersionKind),
+	r := managed.NewReconciler(status.NewManager(mgr, name),
+		resource.ManagedKind(orgv1alpha2.OrganizationGroupVersionKind),
 		managed.WithLogger(nddcopts.Logger.WithValues("controller", name)),
 		managed.WithApplication(&application{
 			client: resource.ClientApplicator{
 				Client:     mgr.GetClient(),
 				Applicator: resource.NewAPIPatchingApplicator(mgr.GetClient()),
 			},
-			log:    nddcopts.Logger.WithValues("applogic", name),
-			newOrg: orgfn,
-			speedy: speedy,
+			log:          nddcopts.Logger.WithValues("applogic", name),
+			controller:   name,
+			newOrg:       orgfn,
+			newDepList:   deplfn,
+			orgNamespace: nddcopts.OrganizationNamespace,
+			depNamespace: nddcopts.DeploymentNamespace,
+			defaults: &defaults.Options{
+				RegisterKinds:        nddcopts.RegisterKinds,
+				RegisterNameTemplate: nddcopts.RegisterNameTemplate,
+			},
+			requeue: policy,
 		}),
-		managed.WithSpeedy(speedy),
 		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
 	)
 
+	depHandler := &EnqueueRequestForDeploymentOrganization{
+		log:          nddcopts.Logger,
+		orgNamespace: nddcopts.OrganizationNamespace,
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o).
-		For(&orgv1alpha1.Organization{}).
-		Owns(&orgv1alpha1.Organization{}).
+		For(&orgv1alpha2.Organization{}, builder.WithPredicates(policy.ResetOnGenerationChange())).
+		Owns(&orgv1alpha2.Organization{}).
 		WithEventFilter(resource.IgnoreUpdateWithoutGenerationChangePredicate()).
 		WithEventFilter(resource.IgnoreUpdateWithoutGenerationChangePredicate()).
-		Complete(r)
+		Watches(&source.Kind{Type: &orgv1alpha2.Deployment{}}, depHandler).
+		Complete(policy.Reconciler(r))
 
 }
 
 type application struct {
-	client resource.ClientApplicator
-	log    logging.Logger
+	client     resource.ClientApplicator
+	log        logging.Logger
+	controller string
 
-	newOrg func() orgv1alpha1.Org
+	newOrg     func() orgv1alpha2.Org
+	newDepList func() orgv1alpha2.DpList
 
-	speedy map[string]int
+	// orgNamespace returns the namespace of the organization of a deployment
+	orgNamespace func(string) string
+	// depNamespace returns the namespace of the deployments of an organization
+	depNamespace func(string) string
+	// defaults of the organization
+	defaults *defaults.Options
 
-	speedyMutex sync.Mutex
+	requeue *requeue.Policy
 }
 
-func getCrName(cr orgv1alpha1.Org) string {
-	return strings.Join([]string{cr.GetNamespace(), cr.GetName()}, ".")
+func getCrName(cr orgv1alpha2.Org) string {
+	return requeue.Key(cr.GetNamespace(), cr.GetName())
 }
 
 func (r *application) Initialize(ctx context.Context, mg resource.Managed) error {
-	cr, ok := mg.(*orgv1alpha1.Organization)
+	cr, ok := mg.(*orgv1alpha2.Organization)
 	if !ok {
 		return errors.New(errUnexpectedResource)
 	}
@@ -106,52 +135,123 @@ func (r *application) Initialize(ctx context.Context, mg resource.Managed) error
 }
 
 func (r *application) Update(ctx context.Context, mg resource.Managed) (map[string]string, error) {
-	cr, ok := mg.(*orgv1alpha1.Organization)
+	cr, ok := mg.(*orgv1alpha2.Organization)
 	if !ok {
 		return nil, errors.New(errUnexpectedResource)
 	}
 
-	return r.handleAppLogic(ctx, cr)
+	ctx, span := tracing.Start(ctx, "organization.handleAppLogic", cr)
+	info, err := r.handleAppLogic(ctx, cr)
+	tracing.End(span, err)
+	return info, err
 }
 
 func (r *application) FinalUpdate(ctx context.Context, mg resource.Managed) {
+	cr, _ := mg.(*orgv1alpha2.Organization)
+	r.requeue.Next(getCrName(cr))
 }
 
 func (r *application) Timeout(ctx context.Context, mg resource.Managed) time.Duration {
-	cr, _ := mg.(*orgv1alpha1.Organization)
-	crName := getCrName(cr)
-	r.speedyMutex.Lock()
-	speedy := r.speedy[crName]
-	r.speedyMutex.Unlock()
-	if speedy <= 5 {
-		r.log.Debug("Speedy", "number", speedy)
-		speedy++
-		return veryShortWait
-	}
-	return reconcileTimeout
+	cr, _ := mg.(*orgv1alpha2.Organization)
+	return r.requeue.Next(getCrName(cr))
 }
 
 func (r *application) Delete(ctx context.Context, mg resource.Managed) (bool, error) {
-	return true, nil
+	cr, ok := mg.(*orgv1alpha2.Organization)
+	if !ok {
+		return false, errors.New(errUnexpectedResource)
+	}
+	log := r.log.WithValues("function", "delete", "crname", cr.GetName())
+
+	deps, err := r.getDeployments(ctx, cr)
+	if err != nil {
+		return false, err
+	}
+	if len(deps) == 0 {
+		return true, nil
+	}
+
+	if err := cr.InitializeResource(); err != nil {
+		return false, err
+	}
+	depNames := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		depNames = append(depNames, dep.GetName())
+	}
+	sort.Strings(depNames)
+	cr.SetBlockingDeployments(depNames)
+
+	if cr.GetDeletionPolicy() != orgv1alpha2.DeletionPolicyCascade {
+		log.Debug("deletion blocked by deployments", "deployments", depNames)
+		cr.SetStatus("down")
+		cr.SetReason("deletion blocked by deployments")
+		return false, nil
+	}
+
+	log.Debug("deleting deployments", "deployments", depNames)
+	cr.SetReason("deleting deployments")
+	for _, dep := range deps {
+		if err := r.client.Delete(ctx, dep); resource.IgnoreNotFound(err) != nil {
+			return false, err
+		}
+	}
+	// wait till the deployments are gone
+	return false, nil
 }
 
 func (r *application) FinalDelete(ctx context.Context, mg resource.Managed) {
-	cr, _ := mg.(*orgv1alpha1.Organization)
-	crName := getCrName(cr)
-	r.speedyMutex.Lock()
-	delete(r.speedy, crName)
-	r.speedyMutex.Unlock()
+	cr, _ := mg.(*orgv1alpha2.Organization)
+	r.requeue.Forget(getCrName(cr))
+}
+
+// getDeployments returns the deployments that reference the organization.
+func (r *application) getDeployments(ctx context.Context, cr orgv1alpha2.Org) ([]orgv1alpha2.Dp, error) {
+	d := r.newDepList()
+	if err := r.client.List(ctx, d,
+		client.InNamespace(r.depNamespace(cr.GetNamespace())),
+		client.MatchingFields{index.DeploymentOrganization: cr.GetName()}); err != nil {
+		return nil, err
+	}
+
+	deps := make([]orgv1alpha2.Dp, 0)
+	for _, dep := range d.GetDeployments() {
+		if r.orgNamespace(dep.GetNamespace()) == cr.GetNamespace() {
+			deps = append(deps, dep)
+		}
+	}
+	return deps, nil
 }
 
-func (r *application) handleAppLogic(ctx context.Context, cr orgv1alpha1.Org) (map[string]string, error) {
+func (r *application) handleAppLogic(ctx context.Context, cr orgv1alpha2.Org) (map[string]string, error) {
 	log := r.log.WithValues("function", "handleAppLogic", "crname", cr.GetName())
 	log.Debug("handleAppLogic")
 
-	register := cr.GetRegister()
-	aas := cr.GetAddressAllocationStrategy()
+	cr.SetBlockingDeployments(nil)
+	required := registry.RequiredRegisters(cr.GetRequiredRegisters())
+	cr.SetStateRequiredRegisters(required)
+	if cr.GetAdminState() == "disable" {
+		reason := "admin state disabled"
+		metrics.AppLogicFailed(r.controller, reason)
+		cr.SetStatus("down")
+		cr.SetReason(reason)
+		cr.SetStateRegister(make(map[string]string))
+		cr.SetStateAddressAllocationStrategy(nil)
+		cr.SetConditions(orgv1alpha2.RequiredRegistersNotEvaluated(orgv1alpha2.ConditionReasonAdminDisabled))
+		return make(map[string]string), nil
+	}
+
+	// the defaults are merged into the status, as the defaulting webhook
+	// merges them into the spec
+	register := resolve.OrganizationRegister(defaults.Register(r.defaults, cr.GetName()), cr.GetRegister())
+	aas, _ := resolve.DeploymentAddressAllocationStrategy(cr.GetAddressAllocationStrategy(), nil)
 	cr.SetStatus("up")
 	cr.SetReason("")
 	cr.SetStateRegister(register)
 	cr.SetStateAddressAllocationStrategy(aas)
+	if missing := registry.MissingRegisters(register, required); len(missing) > 0 {
+		cr.SetConditions(orgv1alpha2.RequiredRegistersMissing(missing))
+	} else {
+		cr.SetConditions(orgv1alpha2.RequiredRegistersPresent())
+	}
 	return make(map[string]string), nil
 }
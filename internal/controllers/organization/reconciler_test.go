@@ -0,0 +1,156 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package organization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/yndd/ndd-runtime/pkg/logging"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	"github.com/yndd/nddo-runtime/pkg/resource"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/index"
+	"github.com/yndd/nddr-organization/internal/index/indextest"
+	"github.com/yndd/nddr-organization/internal/requeue"
+	"github.com/yndd/nddr-organization/internal/shared"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func deployment(namespace, orgName, name string) *orgv1alpha2.Deployment {
+	return &orgv1alpha2.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: orgName + "." + name, Namespace: namespace},
+		Spec: orgv1alpha2.DeploymentSpec{Deployment: &orgv1alpha2.OrgDeployment{
+			OrganizationRef: utils.StringPtr(orgName),
+		}},
+	}
+}
+
+func TestDelete(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	// deployments of another organization or in another namespace are not
+	// governed by the organization
+	others := []client.Object{
+		deployment("default", "other", "region1"),
+		deployment("tenant", "nokia", "region1"),
+	}
+
+	type want struct {
+		deleted     bool
+		blocking    []string
+		status      string
+		deployments []string
+	}
+	cases := map[string]struct {
+		deletionPolicy string
+		deps           []client.Object
+		want           want
+	}{
+		"NoDeployments": {
+			deletionPolicy: orgv1alpha2.DeletionPolicyBlock,
+			want: want{
+				deleted:     true,
+				deployments: []string{"default/other.region1", "tenant/nokia.region1"},
+			},
+		},
+		"BlockedByDeployments": {
+			deletionPolicy: orgv1alpha2.DeletionPolicyBlock,
+			deps: []client.Object{
+				deployment("default", "nokia", "region2"),
+				deployment("default", "nokia", "region1"),
+			},
+			want: want{
+				blocking: []string{"nokia.region1", "nokia.region2"},
+				status:   "down",
+				deployments: []string{
+					"default/nokia.region1", "default/nokia.region2",
+					"default/other.region1", "tenant/nokia.region1",
+				},
+			},
+		},
+		"CascadeDeletesDeployments": {
+			deletionPolicy: orgv1alpha2.DeletionPolicyCascade,
+			deps: []client.Object{
+				deployment("default", "nokia", "region1"),
+				deployment("default", "nokia", "region2"),
+			},
+			want: want{
+				blocking:    []string{"nokia.region1", "nokia.region2"},
+				deployments: []string{"default/other.region1", "tenant/nokia.region1"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			objs := append(append([]client.Object{}, others...), tc.deps...)
+			c := indextest.NewClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build())
+			if err := index.Setup(context.Background(), c); err != nil {
+				t.Fatal(err)
+			}
+			o := &shared.NddControllerOptions{}
+			r := &application{
+				client:       resource.ClientApplicator{Client: c},
+				log:          logging.NewNopLogger(),
+				newDepList:   func() orgv1alpha2.DpList { return &orgv1alpha2.DeploymentList{} },
+				orgNamespace: o.OrganizationNamespace,
+				depNamespace: o.DeploymentNamespace,
+				requeue:      requeue.New(requeue.DefaultOptions()),
+			}
+			org := &orgv1alpha2.Organization{
+				ObjectMeta: metav1.ObjectMeta{Name: "nokia", Namespace: "default"},
+				Spec: orgv1alpha2.OrganizationSpec{Organization: &orgv1alpha2.OrgOrganization{
+					DeletionPolicy: utils.StringPtr(tc.deletionPolicy),
+				}},
+			}
+
+			deleted, err := r.Delete(context.Background(), org)
+			if err != nil {
+				t.Fatalf("Delete(...): %v", err)
+			}
+			if deleted != tc.want.deleted {
+				t.Errorf("Delete(...): want deleted %t, got %t", tc.want.deleted, deleted)
+			}
+			if tc.want.status != "" && org.GetStatus() != tc.want.status {
+				t.Errorf("Delete(...): want status %s, got %s", tc.want.status, org.GetStatus())
+			}
+			if diff := cmp.Diff(tc.want.blocking, org.GetBlockingDeployments(), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Delete(...): blocking deployments -want, +got:\n%s", diff)
+			}
+
+			deps := &orgv1alpha2.DeploymentList{}
+			if err := c.List(context.Background(), deps); err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0)
+			for _, dep := range deps.Items {
+				got = append(got, dep.GetNamespace()+"/"+dep.GetName())
+			}
+			if diff := cmp.Diff(tc.want.deployments, got, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("Delete(...): deployments -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
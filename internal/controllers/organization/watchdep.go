@@ -0,0 +1,77 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package organization
+
+import (
+	"github.com/yndd/ndd-runtime/pkg/logging"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type adder interface {
+	Add(item interface{})
+}
+
+// EnqueueRequestForDeploymentOrganization enqueues the organization a
+// deployment references, such that a blocked organization deletion is
+// re-evaluated when its deployments go away.
+type EnqueueRequestForDeploymentOrganization struct {
+	log logging.Logger
+	// orgNamespace returns the namespace of the organization of a deployment
+	orgNamespace func(string) string
+}
+
+// Create enqueues a request for the organization of the deployment.
+func (e *EnqueueRequestForDeploymentOrganization) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for the organization of the deployment.
+func (e *EnqueueRequestForDeploymentOrganization) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.ObjectOld, q)
+	e.add(evt.ObjectNew, q)
+}
+
+// Delete enqueues a request for the organization of the deployment.
+func (e *EnqueueRequestForDeploymentOrganization) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for the organization of the deployment.
+func (e *EnqueueRequestForDeploymentOrganization) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForDeploymentOrganization) add(obj runtime.Object, queue adder) {
+	dep, ok := obj.(*orgv1alpha2.Deployment)
+	if !ok {
+		return
+	}
+	if dep.GetOrganizationName() == "" {
+		return
+	}
+	log := e.log.WithValues("function", "watch dep", "name", dep.GetName())
+	log.Debug("handleEvent")
+
+	queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+		Namespace: e.orgNamespace(dep.GetNamespace()),
+		Name:      dep.GetOrganizationName()}})
+}
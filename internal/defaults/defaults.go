@@ -0,0 +1,156 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"strings"
+
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/pkg/registry"
+)
+
+const (
+	// OrganizationTemplateVariable is replaced by the organization name in the
+	// register name template.
+	OrganizationTemplateVariable = "{{org}}"
+	// DefaultRegisterNameTemplate is the default name of a defaulted register.
+	DefaultRegisterNameTemplate = OrganizationTemplateVariable + ".default"
+
+	// defaults of the organization and deployment CRD schemas
+	DefaultAdminState     = "enable"
+	DefaultDeploymentKind = "dc"
+	DefaultDeletionPolicy = orgv1alpha2.DeletionPolicyBlock
+	DefaultSkipVerify     = false
+
+	// address allocation strategy defaults
+	DefaultGatewayAllocation          = nddov1.GatewayAllocationFirst
+	DefaultInfraItfcePrefixLengthIpv4 = uint32(31)
+	DefaultInfraItfcePrefixLengthIpv6 = uint32(127)
+)
+
+// DefaultRegisterKinds are the register kinds every organization registers
+// by default.
+var DefaultRegisterKinds = []string{
+	registry.RegisterKindIpam.String(),
+	registry.RegisterKindAs.String(),
+	registry.RegisterKindNetworkInstance.String(),
+	registry.RegisterKindVlan.String(),
+}
+
+// Options configure the organization defaults.
+type Options struct {
+	// RegisterKinds are the register kinds that are added when missing
+	RegisterKinds []string
+	// RegisterNameTemplate is the name of a defaulted register, in which
+	// {{org}} is replaced by the organization name
+	RegisterNameTemplate string
+}
+
+// RegisterName returns the register name derived from the template for the
+// organization.
+func RegisterName(template, orgName string) string {
+	return strings.ReplaceAll(template, OrganizationTemplateVariable, orgName)
+}
+
+// Register returns the default registers of the organization.
+func Register(o *Options, orgName string) map[string]string {
+	r := make(map[string]string)
+	for _, kind := range o.RegisterKinds {
+		r[kind] = RegisterName(o.RegisterNameTemplate, orgName)
+	}
+	return r
+}
+
+// DefaultOrganization fills in the missing register entries and the missing
+// address allocation strategy fields of the organization.
+func DefaultOrganization(cr *orgv1alpha2.Organization, o *Options) {
+	if cr.Spec.Organization == nil {
+		cr.Spec.Organization = &orgv1alpha2.OrgOrganization{}
+	}
+
+	existing := make(map[string]struct{})
+	for _, register := range cr.Spec.Organization.Register {
+		if register != nil && register.Kind != nil {
+			existing[*register.Kind] = struct{}{}
+		}
+	}
+	for _, kind := range o.RegisterKinds {
+		if _, ok := existing[kind]; ok {
+			continue
+		}
+		cr.Spec.Organization.Register = append(cr.Spec.Organization.Register, &nddov1.Register{
+			Kind: utils.StringPtr(kind),
+			Name: utils.StringPtr(RegisterName(o.RegisterNameTemplate, cr.GetName())),
+		})
+	}
+
+	cr.Spec.Organization.AddressAllocationStrategy = DefaultAddressAllocationStrategy(cr.Spec.Organization.AddressAllocationStrategy)
+}
+
+// DefaultOrganizationSchema fills in the fields of the organization that the
+// CRD schema defaults, as the API server does for the organizations it
+// admits. Manifests that are resolved offline are not admitted, hence their
+// schema defaults are applied with this function.
+func DefaultOrganizationSchema(cr *orgv1alpha2.Organization) {
+	if cr.Spec.Organization == nil {
+		cr.Spec.Organization = &orgv1alpha2.OrgOrganization{}
+	}
+	if cr.Spec.Organization.AdminState == nil {
+		cr.Spec.Organization.AdminState = utils.StringPtr(DefaultAdminState)
+	}
+	if cr.Spec.Organization.DeletionPolicy == nil {
+		cr.Spec.Organization.DeletionPolicy = utils.StringPtr(DefaultDeletionPolicy)
+	}
+	if c := cr.Spec.Organization.RegistryCredentials; c != nil && c.SkipVerify == nil {
+		c.SkipVerify = utils.BoolPtr(DefaultSkipVerify)
+	}
+}
+
+// DefaultDeploymentSchema fills in the fields of the deployment that the CRD
+// schema defaults, as DefaultOrganizationSchema does for an organization.
+func DefaultDeploymentSchema(cr *orgv1alpha2.Deployment) {
+	if cr.Spec.Deployment == nil {
+		cr.Spec.Deployment = &orgv1alpha2.OrgDeployment{}
+	}
+	if cr.Spec.Deployment.AdminState == nil {
+		cr.Spec.Deployment.AdminState = utils.StringPtr(DefaultAdminState)
+	}
+	if cr.Spec.Deployment.Kind == nil {
+		cr.Spec.Deployment.Kind = utils.StringPtr(DefaultDeploymentKind)
+	}
+}
+
+// DefaultAddressAllocationStrategy fills in the missing address allocation
+// strategy fields.
+func DefaultAddressAllocationStrategy(aas *nddov1.AddressAllocationStrategy) *nddov1.AddressAllocationStrategy {
+	if aas == nil {
+		aas = &nddov1.AddressAllocationStrategy{}
+	}
+	if aas.GatewayAllocation == nil {
+		gwa := DefaultGatewayAllocation
+		aas.GatewayAllocation = &gwa
+	}
+	if aas.InfraItfcePrefixLengthIpv4 == nil {
+		aas.InfraItfcePrefixLengthIpv4 = utils.Uint32Ptr(DefaultInfraItfcePrefixLengthIpv4)
+	}
+	if aas.InfraItfcePrefixLengthIpv6 == nil {
+		aas.InfraItfcePrefixLengthIpv6 = utils.Uint32Ptr(DefaultInfraItfcePrefixLengthIpv6)
+	}
+	return aas
+}
@@ -0,0 +1,332 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/yndd/ndd-runtime/pkg/logging"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+
+	"github.com/yndd/nddr-organization/pkg/registry"
+	"github.com/yndd/nddr-organization/pkg/registry/registrypb"
+)
+
+const (
+	// DefaultGracePeriod is the time the server waits for the running calls
+	// before it closes them.
+	DefaultGracePeriod = 10 * time.Second
+	// AllNamespaces is the organization of a client certificate that
+	// authorizes the client for all namespaces.
+	AllNamespaces = "*"
+)
+
+// TLSOptions configure the TLS of the server.
+type TLSOptions struct {
+	// CertFile and KeyFile are the serving certificate and key; they are
+	// reloaded when they change
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the ca that verifies the client certificates; when set
+	// every client must present a certificate, and may only query the
+	// namespaces listed as organizations in its subject
+	ClientCAFile string
+}
+
+// Option can be used to manipulate Options.
+type Option func(*Server)
+
+// WithLogger specifies how the Server should log messages.
+func WithLogger(log logging.Logger) Option {
+	return func(s *Server) {
+		s.log = log
+	}
+}
+
+// WithRegistry specifies the registry the Server queries.
+func WithRegistry(r registry.Registry) Option {
+	return func(s *Server) {
+		s.registry = r
+	}
+}
+
+// WithTLS specifies the TLS of the Server.
+func WithTLS(o TLSOptions) Option {
+	return func(s *Server) {
+		s.tls = &o
+	}
+}
+
+// WithInsecure serves without TLS and without authentication of the clients;
+// a Server without TLS only starts with this option.
+func WithInsecure() Option {
+	return func(s *Server) {
+		s.insecure = true
+	}
+}
+
+// WithGracePeriod specifies how long the Server waits for the running calls
+// when it stops.
+func WithGracePeriod(d time.Duration) Option {
+	return func(s *Server) {
+		s.gracePeriod = d
+	}
+}
+
+// Server serves the registers and address allocation strategies of the
+// registry over grpc, such that they can be resolved without a kubernetes
+// client.
+type Server struct {
+	registrypb.UnimplementedRegistryServer
+
+	address     string
+	log         logging.Logger
+	registry    registry.Registry
+	tls         *TLSOptions
+	insecure    bool
+	gracePeriod time.Duration
+
+	// done is closed when the server stops, it ends the watch streams
+	done <-chan struct{}
+}
+
+// New returns a Server that listens on the address.
+func New(address string, opts ...Option) *Server {
+	s := &Server{
+		address:     address,
+		log:         logging.NewNopLogger(),
+		gracePeriod: DefaultGracePeriod,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start serves until the context is done; it implements the manager
+// Runnable. When the context is done the running calls, including the watch
+// streams, are given the grace period to end before they are closed.
+func (s *Server) Start(ctx context.Context) error {
+	// the otel interceptors continue the trace context of the callers
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()),
+	}
+	switch {
+	case s.tls != nil:
+		creds, err := s.credentials(ctx)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	case !s.insecure:
+		return errors.New("registry grpc server requires a tls certificate or an explicit insecure option")
+	}
+
+	l, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+	gs := grpc.NewServer(opts...)
+	registrypb.RegisterRegistryServer(gs, s)
+	s.done = ctx.Done()
+
+	go func() {
+		<-ctx.Done()
+		stopped := make(chan struct{})
+		go func() {
+			gs.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(s.gracePeriod):
+			s.log.Debug("registry grpc server grace period expired, closing the running calls")
+			gs.Stop()
+		}
+	}()
+
+	s.log.Debug("starting registry grpc server", "address", s.address)
+	return gs.Serve(l)
+}
+
+// credentials returns the server TLS credentials; the serving certificate is
+// watched for changes until the context is done.
+func (s *Server) credentials(ctx context.Context) (credentials.TransportCredentials, error) {
+	w, err := certwatcher.New(s.tls.CertFile, s.tls.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load registry grpc server certificate: %v", err)
+	}
+	go func() {
+		if err := w.Start(ctx); err != nil {
+			s.log.Debug("cannot watch registry grpc server certificate", "error", err)
+		}
+	}()
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: w.GetCertificate,
+	}
+	if s.tls.ClientCAFile != "" {
+		ca, err := os.ReadFile(s.tls.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read registry grpc server client ca: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid registry grpc server client ca %s", s.tls.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// authorize returns an error when the client may not query the namespace.
+// Without a client ca every client may query every namespace.
+func (s *Server) authorize(ctx context.Context, namespace string) error {
+	if s.tls == nil || s.tls.ClientCAFile == "" {
+		return nil
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "no peer")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+	subject := tlsInfo.State.VerifiedChains[0][0].Subject
+	for _, ns := range subject.Organization {
+		if ns == namespace || ns == AllNamespaces {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "client %s may not query namespace %s", subject.CommonName, namespace)
+}
+
+// NeedLeaderElection returns false, every replica serves queries.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) GetRegister(ctx context.Context, req *registrypb.Request) (*registrypb.RegisterReply, error) {
+	if err := s.authorize(ctx, req.GetNamespace()); err != nil {
+		return nil, err
+	}
+	register, err := s.registry.GetRegister(ctx, req.GetNamespace(), req.GetRegisterName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &registrypb.RegisterReply{Register: register}, nil
+}
+
+func (s *Server) GetAddressAllocationStrategy(ctx context.Context, req *registrypb.Request) (*registrypb.AddressAllocationStrategyReply, error) {
+	if err := s.authorize(ctx, req.GetNamespace()); err != nil {
+		return nil, err
+	}
+	aas, err := s.registry.GetAddressAllocationStrategy(ctx, req.GetNamespace(), req.GetRegisterName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &registrypb.AddressAllocationStrategyReply{AddressAllocationStrategy: toAddressAllocationStrategy(aas)}, nil
+}
+
+// Watch streams the register events until the client cancels the stream or
+// the server stops.
+func (s *Server) Watch(req *registrypb.Request, stream registrypb.Registry_WatchServer) error {
+	if err := s.authorize(stream.Context(), req.GetNamespace()); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ch, err := s.registry.Watch(ctx, req.GetNamespace(), req.GetRegisterName())
+	if err != nil {
+		return toStatus(err)
+	}
+	// the header tells the client the watch is established
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for e := range ch {
+		if err := stream.Send(&registrypb.WatchEvent{
+			Namespace:                    e.Namespace,
+			RegisterName:                 e.RegisterName,
+			OldRegister:                  e.OldRegister,
+			NewRegister:                  e.NewRegister,
+			OldAddressAllocationStrategy: toAddressAllocationStrategy(e.OldAddressAllocationStrategy),
+			NewAddressAllocationStrategy: toAddressAllocationStrategy(e.NewAddressAllocationStrategy),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toAddressAllocationStrategy(aas *nddov1.AddressAllocationStrategy) *registrypb.AddressAllocationStrategy {
+	if aas == nil {
+		return nil
+	}
+	a := &registrypb.AddressAllocationStrategy{}
+	if aas.GatewayAllocation != nil {
+		a.GatewayAllocation = aas.GatewayAllocation.String()
+	}
+	if aas.InfraItfcePrefixLengthIpv4 != nil {
+		a.InfraItfcePrefixLengthIpv4 = *aas.InfraItfcePrefixLengthIpv4
+	}
+	if aas.InfraItfcePrefixLengthIpv6 != nil {
+		a.InfraItfcePrefixLengthIpv6 = *aas.InfraItfcePrefixLengthIpv6
+	}
+	return a
+}
+
+// toStatus maps the registry errors to grpc status codes
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, registry.ErrInvalidRegisterName):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, registry.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, &registry.ErrCriticalRegisterMissing{}):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, registry.ErrBackendUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
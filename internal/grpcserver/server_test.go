@@ -0,0 +1,86 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/yndd/nddr-organization/pkg/registry/registrypb"
+	"github.com/yndd/nddr-organization/pkg/registry/registrytest"
+)
+
+// TestStopEndsWatch verifies that a running watch stream does not keep the
+// server from stopping within its grace period.
+func TestStopEndsWatch(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := l.Addr().String()
+	l.Close()
+
+	s := New(address,
+		WithRegistry(registrytest.New()),
+		WithInsecure(),
+		WithGracePeriod(time.Minute),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Start(ctx) }()
+
+	dialCtx, dialCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer dialCancel()
+	conn, err := grpc.DialContext(dialCtx, address, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("DialContext(...): %v", err)
+	}
+	defer conn.Close()
+	stream, err := registrypb.NewRegistryClient(conn).Watch(context.Background(), &registrypb.Request{
+		Namespace:    "default",
+		RegisterName: "nokia",
+	})
+	if err != nil {
+		t.Fatalf("Watch(...): %v", err)
+	}
+	// wait until the watch is running on the server
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("Header(): %v", err)
+	}
+
+	cancel()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Start(...): %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start(...): server did not stop with a running watch")
+	}
+}
+
+func TestStartRequiresTLSOrInsecure(t *testing.T) {
+	s := New("127.0.0.1:0", WithRegistry(registrytest.New()))
+	if err := s.Start(context.Background()); err == nil {
+		t.Error("Start(...): want error without tls or insecure option")
+	}
+}
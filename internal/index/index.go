@@ -0,0 +1,42 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"context"
+
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DeploymentOrganization indexes the deployments by the name of the
+	// organization they reference
+	DeploymentOrganization = "spec.deployment.organization-ref"
+)
+
+// Setup adds the field indexes used by the controllers; it must be called
+// before the manager is started.
+func Setup(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &orgv1alpha2.Deployment{}, DeploymentOrganization, func(o client.Object) []string {
+		dep, ok := o.(*orgv1alpha2.Deployment)
+		if !ok || dep.GetOrganizationName() == "" {
+			return nil
+		}
+		return []string{dep.GetOrganizationName()}
+	})
+}
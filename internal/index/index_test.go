@@ -0,0 +1,76 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// indexer records the index functions per field
+type indexer map[string]client.IndexerFunc
+
+func (i indexer) IndexField(ctx context.Context, obj client.Object, field string, fn client.IndexerFunc) error {
+	i[field] = fn
+	return nil
+}
+
+func TestDeploymentOrganizationIndex(t *testing.T) {
+	i := indexer{}
+	if err := Setup(context.Background(), i); err != nil {
+		t.Fatal(err)
+	}
+	fn, ok := i[DeploymentOrganization]
+	if !ok {
+		t.Fatalf("Setup(...): index %s not added", DeploymentOrganization)
+	}
+
+	cases := map[string]struct {
+		obj  client.Object
+		want []string
+	}{
+		"OrganizationRef": {
+			obj: &orgv1alpha2.Deployment{Spec: orgv1alpha2.DeploymentSpec{
+				Deployment: &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia")},
+			}},
+			want: []string{"nokia"},
+		},
+		"NoOrganizationRef": {
+			obj: &orgv1alpha2.Deployment{Spec: orgv1alpha2.DeploymentSpec{
+				Deployment: &orgv1alpha2.OrgDeployment{},
+			}},
+		},
+		"NoSpec": {
+			obj: &orgv1alpha2.Deployment{},
+		},
+		"NotADeployment": {
+			obj: &orgv1alpha2.Organization{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, fn(tc.obj)); diff != "" {
+				t.Errorf("index %s: -want, +got:\n%s", DeploymentOrganization, diff)
+			}
+		})
+	}
+}
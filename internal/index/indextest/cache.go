@@ -0,0 +1,152 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package indextest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var _ cache.Cache = &Cache{}
+
+// Cache is a cache.Cache that reads with an indexed Client and returns
+// Informers that deliver the events sent to them by the test.
+type Cache struct {
+	*Client
+
+	m         sync.Mutex
+	informers map[schema.GroupVersionKind]*Informer
+}
+
+// NewCache returns a Cache that reads with the client.
+func NewCache(c client.Client) *Cache {
+	return &Cache{
+		Client:    NewClient(c),
+		informers: make(map[schema.GroupVersionKind]*Informer),
+	}
+}
+
+// Informer returns the informer of the type of obj.
+func (c *Cache) Informer(obj client.Object) (*Informer, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	return c.informer(gvk), nil
+}
+
+func (c *Cache) informer(gvk schema.GroupVersionKind) *Informer {
+	c.m.Lock()
+	defer c.m.Unlock()
+	i, ok := c.informers[gvk]
+	if !ok {
+		i = &Informer{}
+		c.informers[gvk] = i
+	}
+	return i
+}
+
+// GetInformer returns the informer of the type of obj.
+func (c *Cache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	return c.Informer(obj)
+}
+
+// GetInformerForKind returns the informer of the kind.
+func (c *Cache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	return c.informer(gvk), nil
+}
+
+// Start does nothing; the informers deliver the events sent by the test.
+func (c *Cache) Start(ctx context.Context) error {
+	return nil
+}
+
+// WaitForCacheSync returns true; the cache is always synced.
+func (c *Cache) WaitForCacheSync(ctx context.Context) bool {
+	return true
+}
+
+// Informer is a cache.Informer that delivers the events sent with Add,
+// Update and Delete to its handlers.
+type Informer struct {
+	m        sync.Mutex
+	handlers []toolscache.ResourceEventHandler
+}
+
+// AddEventHandler adds the handler.
+func (i *Informer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	i.m.Lock()
+	defer i.m.Unlock()
+	i.handlers = append(i.handlers, handler)
+}
+
+// AddEventHandlerWithResyncPeriod adds the handler; the informer never
+// resyncs.
+func (i *Informer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler, resyncPeriod time.Duration) {
+	i.AddEventHandler(handler)
+}
+
+// AddIndexers does nothing; the cache is indexed with IndexField.
+func (i *Informer) AddIndexers(indexers toolscache.Indexers) error {
+	return nil
+}
+
+// HasSynced returns true.
+func (i *Informer) HasSynced() bool {
+	return true
+}
+
+// Handlers returns the number of handlers added to the informer.
+func (i *Informer) Handlers() int {
+	i.m.Lock()
+	defer i.m.Unlock()
+	return len(i.handlers)
+}
+
+// Add delivers an add event of obj to the handlers.
+func (i *Informer) Add(obj interface{}) {
+	for _, h := range i.getHandlers() {
+		h.OnAdd(obj)
+	}
+}
+
+// Update delivers an update event of the object to the handlers.
+func (i *Informer) Update(oldObj, newObj interface{}) {
+	for _, h := range i.getHandlers() {
+		h.OnUpdate(oldObj, newObj)
+	}
+}
+
+// Delete delivers a delete event of obj to the handlers.
+func (i *Informer) Delete(obj interface{}) {
+	for _, h := range i.getHandlers() {
+		h.OnDelete(obj)
+	}
+}
+
+func (i *Informer) getHandlers() []toolscache.ResourceEventHandler {
+	i.m.Lock()
+	defer i.m.Unlock()
+	return append([]toolscache.ResourceEventHandler(nil), i.handlers...)
+}
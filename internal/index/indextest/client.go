@@ -0,0 +1,127 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package indextest provides a client and an informer cache with field
+// indexes for the unit tests of the components that list with
+// client.MatchingFields, which the fake client does not support.
+package indextest
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var _ client.FieldIndexer = &Client{}
+
+type indexKey struct {
+	gvk   schema.GroupVersionKind
+	field string
+}
+
+// Client is a client.Client that filters the lists by the field indexes
+// added with IndexField, as the informer cache does. The lists are read from
+// the wrapped client, typically a fake client.
+type Client struct {
+	client.Client
+	indexes map[indexKey]client.IndexerFunc
+}
+
+// NewClient returns a Client that wraps the client.
+func NewClient(c client.Client) *Client {
+	return &Client{
+		Client:  c,
+		indexes: make(map[indexKey]client.IndexerFunc),
+	}
+}
+
+// IndexField adds the index function of the field for the type of obj.
+func (c *Client) IndexField(ctx context.Context, obj client.Object, field string, fn client.IndexerFunc) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	c.indexes[indexKey{gvk: gvk, field: field}] = fn
+	return nil
+}
+
+// List lists the objects of the wrapped client and keeps the objects that
+// match the field selector on the indexed fields; a field selector on a field
+// that is not indexed is an error, as it is for the informer cache.
+func (c *Client) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	o := &client.ListOptions{}
+	o.ApplyOptions(opts)
+	fieldSelector := o.FieldSelector
+	o.FieldSelector = nil
+	if err := c.Client.List(ctx, list, o); err != nil {
+		return err
+	}
+	if fieldSelector == nil || fieldSelector.Empty() {
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	kept := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(client.Object)
+		if !ok {
+			return fmt.Errorf("list item %T is not an object", item)
+		}
+		match, err := c.matches(obj, fieldSelector)
+		if err != nil {
+			return err
+		}
+		if match {
+			kept = append(kept, item)
+		}
+	}
+	return meta.SetList(list, kept)
+}
+
+// matches returns true when every requirement of the selector matches a
+// value of the index of its field.
+func (c *Client) matches(obj client.Object, selector fields.Selector) (bool, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return false, err
+	}
+	for _, r := range selector.Requirements() {
+		fn, ok := c.indexes[indexKey{gvk: gvk, field: r.Field}]
+		if !ok {
+			return false, fmt.Errorf("index with name field:%s does not exist", r.Field)
+		}
+		found := false
+		for _, v := range fn(obj) {
+			if v == r.Value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifests
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	orgv1alpha1 "github.com/yndd/nddr-organization/apis/org/v1alpha1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultNamespace is the namespace of objects without a namespace
+	DefaultNamespace = "default"
+)
+
+// Source is the location of an object in the manifests
+type Source struct {
+	Path string `json:"path"`
+	// Document is the index of the yaml document in the file, starting at 0
+	Document int `json:"document"`
+}
+
+func (s Source) String() string {
+	return fmt.Sprintf("%s[%d]", s.Path, s.Document)
+}
+
+// Organization is an organization loaded from a manifest
+type Organization struct {
+	Source
+	*orgv1alpha2.Organization
+}
+
+// Deployment is a deployment loaded from a manifest
+type Deployment struct {
+	Source
+	*orgv1alpha2.Deployment
+}
+
+// Manifests are the organizations and deployments loaded from manifests;
+// v1alpha1 objects are converted to v1alpha2.
+type Manifests struct {
+	Organizations []*Organization
+	Deployments   []*Deployment
+}
+
+// Load loads the organizations and deployments of the yaml files; the yaml
+// files in a directory are loaded recursively. Objects of other kinds are
+// skipped.
+func Load(paths ...string) (*Manifests, error) {
+	m := &Manifests{}
+	for _, path := range paths {
+		if err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// files are loaded whatever their extension when given explicitly
+			if p != path && !isYAML(p) {
+				return nil
+			}
+			return m.loadFile(p)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Organization returns the organization with the name in the namespace.
+func (m *Manifests) Organization(namespace, name string) *Organization {
+	for _, org := range m.Organizations {
+		if org.GetNamespace() == namespace && org.GetName() == name {
+			return org
+		}
+	}
+	return nil
+}
+
+func isYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func (m *Manifests) loadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
+	for i := 0; ; i++ {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read %s: %v", path, err)
+		}
+		if err := m.loadDocument(Source{Path: path, Document: i}, doc); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *Manifests) loadDocument(src Source, doc []byte) error {
+	if len(bytes.TrimSpace(doc)) == 0 {
+		return nil
+	}
+	tm := struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}{}
+	if err := yaml.Unmarshal(doc, &tm); err != nil {
+		return fmt.Errorf("cannot parse %s: %v", src, err)
+	}
+	gv, err := schema.ParseGroupVersion(tm.APIVersion)
+	if err != nil || gv.Group != orgv1alpha2.Group {
+		return nil
+	}
+
+	switch {
+	case gv.Version == orgv1alpha2.GroupVersion.Version && tm.Kind == orgv1alpha2.OrganizationKindKind:
+		org := &orgv1alpha2.Organization{}
+		if err := yaml.UnmarshalStrict(doc, org); err != nil {
+			return fmt.Errorf("cannot parse %s: %v", src, err)
+		}
+		m.addOrganization(src, org)
+	case gv.Version == orgv1alpha2.GroupVersion.Version && tm.Kind == orgv1alpha2.DeploymentKindKind:
+		dep := &orgv1alpha2.Deployment{}
+		if err := yaml.UnmarshalStrict(doc, dep); err != nil {
+			return fmt.Errorf("cannot parse %s: %v", src, err)
+		}
+		m.addDeployment(src, dep)
+	case gv.Version == orgv1alpha1.GroupVersion.Version && tm.Kind == orgv1alpha1.OrganizationKindKind:
+		v1org := &orgv1alpha1.Organization{}
+		if err := yaml.UnmarshalStrict(doc, v1org); err != nil {
+			return fmt.Errorf("cannot parse %s: %v", src, err)
+		}
+		org := &orgv1alpha2.Organization{}
+		if err := v1org.ConvertTo(org); err != nil {
+			return fmt.Errorf("cannot convert %s: %v", src, err)
+		}
+		m.addOrganization(src, org)
+	case gv.Version == orgv1alpha1.GroupVersion.Version && tm.Kind == orgv1alpha1.DeploymentKindKind:
+		v1dep := &orgv1alpha1.Deployment{}
+		if err := yaml.UnmarshalStrict(doc, v1dep); err != nil {
+			return fmt.Errorf("cannot parse %s: %v", src, err)
+		}
+		dep := &orgv1alpha2.Deployment{}
+		if err := v1dep.ConvertTo(dep); err != nil {
+			return fmt.Errorf("cannot convert %s: %v", src, err)
+		}
+		m.addDeployment(src, dep)
+	}
+	return nil
+}
+
+func (m *Manifests) addOrganization(src Source, org *orgv1alpha2.Organization) {
+	if org.GetNamespace() == "" {
+		org.SetNamespace(DefaultNamespace)
+	}
+	if org.Spec.Organization == nil {
+		org.Spec.Organization = &orgv1alpha2.OrgOrganization{}
+	}
+	m.Organizations = append(m.Organizations, &Organization{Source: src, Organization: org})
+}
+
+func (m *Manifests) addDeployment(src Source, dep *orgv1alpha2.Deployment) {
+	if dep.GetNamespace() == "" {
+		dep.SetNamespace(DefaultNamespace)
+	}
+	if dep.Spec.Deployment == nil {
+		dep.Spec.Deployment = &orgv1alpha2.OrgDeployment{}
+	}
+	m.Deployments = append(m.Deployments, &Deployment{Source: src, Deployment: dep})
+}
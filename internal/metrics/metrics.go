@@ -0,0 +1,124 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "nddr"
+	subsystem = "organization"
+
+	// ReasonError labels the application logic failures caused by an error
+	ReasonError = "error"
+
+	collectTimeout = 10 * time.Second
+)
+
+var (
+	appLogicFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "applogic_failures_total",
+		Help:      "Number of reconciles that left a resource down or failed, by controller and reason.",
+	}, []string{"controller", "reason"})
+	fanOut = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "change_fanout_deployments",
+		Help:      "Number of deployments enqueued per organization change.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+
+	organizationsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "organizations"),
+		"Number of organizations by status and admin state.",
+		[]string{"status", "admin_state"}, nil)
+	deploymentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "deployments"),
+		"Number of deployments by status, admin state, kind and region.",
+		[]string{"status", "admin_state", "kind", "region"}, nil)
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(appLogicFailures, fanOut)
+}
+
+// AppLogicFailed counts a reconcile of the controller that left the resource
+// down or failed for the reason.
+func AppLogicFailed(controller, reason string) {
+	appLogicFailures.WithLabelValues(controller, reason).Inc()
+}
+
+// ObserveFanOut records the number of deployments enqueued for an
+// organization change.
+func ObserveFanOut(n int) {
+	fanOut.Observe(float64(n))
+}
+
+// Setup registers the collector of the organization and deployment gauges,
+// which reads the resources from the client when the metrics are scraped.
+func Setup(c client.Reader) error {
+	return crmetrics.Registry.Register(&collector{client: c})
+}
+
+type collector struct {
+	client client.Reader
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- organizationsDesc
+	ch <- deploymentsDesc
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	orgs := &orgv1alpha2.OrganizationList{}
+	if err := c.client.List(ctx, orgs); err != nil {
+		ch <- prometheus.NewInvalidMetric(organizationsDesc, err)
+	} else {
+		counts := make(map[[2]string]int)
+		for _, org := range orgs.GetOrganizations() {
+			counts[[2]string{org.GetStatus(), org.GetAdminState()}]++
+		}
+		for l, n := range counts {
+			ch <- prometheus.MustNewConstMetric(organizationsDesc, prometheus.GaugeValue, float64(n), l[0], l[1])
+		}
+	}
+
+	deps := &orgv1alpha2.DeploymentList{}
+	if err := c.client.List(ctx, deps); err != nil {
+		ch <- prometheus.NewInvalidMetric(deploymentsDesc, err)
+	} else {
+		counts := make(map[[4]string]int)
+		for _, dep := range deps.GetDeployments() {
+			counts[[4]string{dep.GetStatus(), dep.GetAdminState(), dep.GetKind(), dep.GetRegion()}]++
+		}
+		for l, n := range counts {
+			ch <- prometheus.MustNewConstMetric(deploymentsDesc, prometheus.GaugeValue, float64(n), l[0], l[1], l[2], l[3])
+		}
+	}
+}
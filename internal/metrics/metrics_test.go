@@ -0,0 +1,51 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCollectWithoutSpec(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&orgv1alpha2.Organization{ObjectMeta: metav1.ObjectMeta{Name: "nokia", Namespace: "default"}},
+		&orgv1alpha2.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "nokia.region1", Namespace: "default"}},
+	).Build()
+
+	want := `
+# HELP nddr_organization_deployments Number of deployments by status, admin state, kind and region.
+# TYPE nddr_organization_deployments gauge
+nddr_organization_deployments{admin_state="",kind="",region="",status="unknown"} 1
+# HELP nddr_organization_organizations Number of organizations by status and admin state.
+# TYPE nddr_organization_organizations gauge
+nddr_organization_organizations{admin_state="",status="unknown"} 1
+`
+	if err := testutil.CollectAndCompare(&collector{client: c}, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
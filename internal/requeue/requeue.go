@@ -0,0 +1,166 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requeue
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// defaults
+	DefaultFastCount      = 5
+	DefaultFastInterval   = 1 * time.Second
+	DefaultSteadyInterval = 1 * time.Minute
+	DefaultJitter         = 0.1
+)
+
+// Options configure a requeue Policy.
+type Options struct {
+	// FastCount is the number of reconciles after a change that requeue
+	// after the FastInterval
+	FastCount int
+	// FastInterval is the requeue interval of the fast phase
+	FastInterval time.Duration
+	// SteadyInterval is the requeue interval after the fast phase
+	SteadyInterval time.Duration
+	// Jitter is the maximum fraction of the interval added to it, such that
+	// the requeues of many resources spread out
+	Jitter float64
+}
+
+// DefaultOptions returns the default requeue options.
+func DefaultOptions() Options {
+	return Options{
+		FastCount:      DefaultFastCount,
+		FastInterval:   DefaultFastInterval,
+		SteadyInterval: DefaultSteadyInterval,
+		Jitter:         DefaultJitter,
+	}
+}
+
+// A Policy decides when a successfully reconciled resource is requeued. A
+// resource is requeued FastCount times after the FastInterval when it changed
+// or was Reset, and after the SteadyInterval afterwards.
+type Policy struct {
+	opts Options
+
+	mutex sync.Mutex
+	// counts holds the number of fast requeues per resource
+	counts map[string]int
+	// pending holds the requeue interval of the ongoing reconcile per resource
+	pending map[string]time.Duration
+}
+
+// New returns a requeue Policy.
+func New(o Options) *Policy {
+	return &Policy{
+		opts:    o,
+		counts:  make(map[string]int),
+		pending: make(map[string]time.Duration),
+	}
+}
+
+// Key returns the key of the resource with the namespace and name.
+func Key(namespace, name string) string {
+	return strings.Join([]string{namespace, name}, ".")
+}
+
+// Next returns the requeue interval of the ongoing reconcile of the resource
+// and advances its fast phase; calling it again within the same reconcile
+// returns the same interval.
+func (p *Policy) Next(key string) time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if d, ok := p.pending[key]; ok {
+		return d
+	}
+	d := p.opts.SteadyInterval
+	if p.counts[key] < p.opts.FastCount {
+		p.counts[key]++
+		d = p.opts.FastInterval
+	}
+	if p.opts.Jitter > 0 {
+		d = wait.Jitter(d, p.opts.Jitter)
+	}
+	p.pending[key] = d
+	return d
+}
+
+// Reset starts a new fast phase for the resource.
+func (p *Policy) Reset(key string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.counts, key)
+}
+
+// Forget removes the state of a deleted resource.
+func (p *Policy) Forget(key string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.counts, key)
+	delete(p.pending, key)
+}
+
+// ResetOnGenerationChange returns a predicate that starts a new fast phase
+// for a resource whose generation changed; it filters no events.
+func (p *Policy) ResetOnGenerationChange() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld != nil && e.ObjectNew != nil &&
+				e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() {
+				p.Reset(Key(e.ObjectNew.GetNamespace(), e.ObjectNew.GetName()))
+			}
+			return true
+		},
+	}
+}
+
+func (p *Policy) take(key string) (time.Duration, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	d, ok := p.pending[key]
+	delete(p.pending, key)
+	return d, ok
+}
+
+// Reconciler returns a reconciler that requeues the reconciles of the
+// reconciler after the interval of the policy when the application called
+// Next during the reconcile; other reconciles keep their result.
+func (p *Policy) Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	return &reconciler{Reconciler: r, policy: p}
+}
+
+type reconciler struct {
+	reconcile.Reconciler
+	policy *Policy
+}
+
+func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	result, err := r.Reconciler.Reconcile(ctx, req)
+	if d, ok := r.policy.take(Key(req.Namespace, req.Name)); ok && err == nil {
+		return reconcile.Result{RequeueAfter: d}, nil
+	}
+	return result, err
+}
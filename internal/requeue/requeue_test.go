@@ -0,0 +1,214 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requeue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var testOptions = Options{
+	FastCount:      2,
+	FastInterval:   1 * time.Second,
+	SteadyInterval: 1 * time.Minute,
+}
+
+// reconcileNext mimics a reconcile in which the application calls Next and
+// returns the interval of the reconcile.
+func reconcileNext(p *Policy, key string) time.Duration {
+	d := p.Next(key)
+	p.take(key)
+	return d
+}
+
+func TestNext(t *testing.T) {
+	p := New(testOptions)
+	got := make([]time.Duration, 0)
+	for i := 0; i < 4; i++ {
+		got = append(got, reconcileNext(p, "ns.a"))
+	}
+	want := []time.Duration{time.Second, time.Second, time.Minute, time.Minute}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Next(...): -want, +got:\n%s", diff)
+	}
+
+	// the other resources have their own fast phase
+	if got := reconcileNext(p, "ns.b"); got != time.Second {
+		t.Errorf("Next(...) of another resource: want %s, got %s", time.Second, got)
+	}
+}
+
+func TestNextWithinReconcile(t *testing.T) {
+	p := New(testOptions)
+	if first, second := p.Next("ns.a"), p.Next("ns.a"); first != second {
+		t.Errorf("Next(...) within a reconcile: want %s, got %s", first, second)
+	}
+	if got := p.counts["ns.a"]; got != 1 {
+		t.Errorf("Next(...) within a reconcile: want count 1, got %d", got)
+	}
+}
+
+func TestNextJitter(t *testing.T) {
+	o := testOptions
+	o.Jitter = 0.5
+	p := New(o)
+	for i := 0; i < 10; i++ {
+		got := reconcileNext(p, "ns.a")
+		if got < time.Minute && (got < time.Second || got > 1500*time.Millisecond) {
+			t.Errorf("Next(...): fast interval %s out of the jitter range", got)
+		}
+		if got >= time.Minute && got > 90*time.Second {
+			t.Errorf("Next(...): steady interval %s out of the jitter range", got)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	p := New(testOptions)
+	for i := 0; i < 3; i++ {
+		reconcileNext(p, "ns.a")
+	}
+	p.Reset("ns.a")
+	if got := reconcileNext(p, "ns.a"); got != time.Second {
+		t.Errorf("Next(...) after Reset: want %s, got %s", time.Second, got)
+	}
+}
+
+func TestForget(t *testing.T) {
+	p := New(testOptions)
+	for i := 0; i < 3; i++ {
+		reconcileNext(p, "ns.a")
+	}
+	// a deleted resource forgets its ongoing reconcile too
+	p.Next("ns.a")
+	p.Forget("ns.a")
+	if _, ok := p.take("ns.a"); ok {
+		t.Errorf("Forget(...): want no pending interval")
+	}
+	if got := reconcileNext(p, "ns.a"); got != time.Second {
+		t.Errorf("Next(...) after Forget: want %s, got %s", time.Second, got)
+	}
+}
+
+func TestResetOnGenerationChange(t *testing.T) {
+	dep := func(generation int64) *orgv1alpha2.Deployment {
+		return &orgv1alpha2.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns", Name: "a", Generation: generation,
+		}}
+	}
+
+	cases := map[string]struct {
+		old  int64
+		new  int64
+		want time.Duration
+	}{
+		"GenerationChanged": {
+			old:  1,
+			new:  2,
+			want: time.Second,
+		},
+		"GenerationUnchanged": {
+			old:  1,
+			new:  1,
+			want: time.Minute,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New(testOptions)
+			for i := 0; i < 3; i++ {
+				reconcileNext(p, "ns.a")
+			}
+			if !p.ResetOnGenerationChange().Update(event.UpdateEvent{ObjectOld: dep(tc.old), ObjectNew: dep(tc.new)}) {
+				t.Errorf("ResetOnGenerationChange(): want the update not to be filtered")
+			}
+			if got := reconcileNext(p, "ns.a"); got != tc.want {
+				t.Errorf("Next(...): want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+// reconcilerFn is a reconciler that calls the function
+type reconcilerFn func() (reconcile.Result, error)
+
+func (fn reconcilerFn) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	return fn()
+}
+
+func TestReconciler(t *testing.T) {
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "ns", Name: "a"}}
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		next       bool
+		result     reconcile.Result
+		err        error
+		wantResult reconcile.Result
+		wantErr    error
+	}{
+		"FinalUpdate": {
+			next:       true,
+			result:     reconcile.Result{RequeueAfter: time.Hour},
+			wantResult: reconcile.Result{RequeueAfter: time.Second},
+		},
+		"NoFinalUpdate": {
+			result:     reconcile.Result{RequeueAfter: time.Hour},
+			wantResult: reconcile.Result{RequeueAfter: time.Hour},
+		},
+		"Error": {
+			next:       true,
+			result:     reconcile.Result{Requeue: true},
+			err:        errBoom,
+			wantResult: reconcile.Result{Requeue: true},
+			wantErr:    errBoom,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := New(testOptions)
+			r := p.Reconciler(reconcilerFn(func() (reconcile.Result, error) {
+				if tc.next {
+					// the application calls Next in its FinalUpdate
+					p.Next(Key(req.Namespace, req.Name))
+				}
+				return tc.result, tc.err
+			}))
+			got, err := r.Reconcile(context.Background(), req)
+			if err != tc.wantErr {
+				t.Errorf("Reconcile(...): want error %v, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.wantResult, got); diff != "" {
+				t.Errorf("Reconcile(...): -want, +got:\n%s", diff)
+			}
+			if _, ok := p.take(Key(req.Namespace, req.Name)); ok {
+				t.Errorf("Reconcile(...): want the pending interval taken")
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolve
+
+import (
+	"sort"
+
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/pkg/registry"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	ReasonOrganizationNotFound      = "organization not found"
+	ReasonOrganizationAdminDisabled = "organization admin state disabled"
+	ReasonAdminDisabled             = "admin state disabled"
+
+	adminStateDisable = "disable"
+)
+
+// Deployment is the effective state of a deployment
+type Deployment struct {
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name"`
+	Organization string `json:"organization"`
+	Status       string `json:"status"`
+	Reason       string `json:"reason,omitempty"`
+	// Register is the effective register per kind and RegisterSource its
+	// provenance
+	Register       map[string]string `json:"register"`
+	RegisterSource map[string]string `json:"register-source,omitempty"`
+	// Overrides are the register kinds the deployment overrides
+	Overrides                       []string                                            `json:"overrides,omitempty"`
+	RequiredRegisters               []string                                            `json:"required-registers,omitempty"`
+	MissingRegisters                []string                                            `json:"missing-registers,omitempty"`
+	AddressAllocationStrategy       *nddov1.AddressAllocationStrategy                   `json:"address-allocation-strategy,omitempty"`
+	AddressAllocationStrategySource *orgv1alpha2.NddrOrgAddressAllocationStrategySource `json:"address-allocation-strategy-source,omitempty"`
+}
+
+// ResolveDeployment returns the effective state of the deployment within its
+// organization; org is nil when the organization of the deployment is not
+// found.
+func ResolveDeployment(dep orgv1alpha2.Dp, org orgv1alpha2.Org, o *defaults.Options) *Deployment {
+	d := &Deployment{
+		Namespace:    dep.GetNamespace(),
+		Name:         dep.GetName(),
+		Organization: dep.GetOrganizationName(),
+		Register:     make(map[string]string),
+	}
+	if org == nil {
+		d.Status = StatusDown
+		d.Reason = ReasonOrganizationNotFound
+		return d
+	}
+
+	d.RequiredRegisters = registry.RequiredRegisters(org.GetDeploymentRequiredRegisters(dep.GetKind()))
+	switch {
+	case org.GetAdminState() == adminStateDisable:
+		d.Status = StatusDown
+		d.Reason = ReasonOrganizationAdminDisabled
+	case dep.GetAdminState() == adminStateDisable:
+		d.Status = StatusDown
+		d.Reason = ReasonAdminDisabled
+	default:
+		d.Status = StatusUp
+		defRegister := defaults.Register(o, dep.GetOrganizationName())
+		d.Register, d.RegisterSource, d.Overrides = DeploymentRegister(defRegister, org.GetRegister(), dep.GetRegister())
+		d.MissingRegisters = registry.MissingRegisters(d.Register, d.RequiredRegisters)
+		d.AddressAllocationStrategy, d.AddressAllocationStrategySource = DeploymentAddressAllocationStrategy(org.GetAddressAllocationStrategy(), dep.GetAddressAllocationStrategy())
+	}
+	return d
+}
+
+// DeploymentRegister merges the default, organization and deployment
+// registers, in that order. It returns the register, the source of every
+// register kind and the kinds for which the deployment overrides the
+// organization.
+func DeploymentRegister(defRegister, orgRegister, depRegister map[string]string) (map[string]string, map[string]string, []string) {
+	register := make(map[string]string)
+	source := make(map[string]string)
+	for kind, name := range defRegister {
+		register[kind] = name
+		source[kind] = orgv1alpha2.RegisterSourceDefault
+	}
+	for kind, name := range orgRegister {
+		register[kind] = name
+		source[kind] = orgv1alpha2.RegisterSourceOrganization
+	}
+	overrides := make([]string, 0)
+	for kind, name := range depRegister {
+		if orgName, ok := orgRegister[kind]; ok && orgName != name {
+			overrides = append(overrides, kind)
+		}
+		register[kind] = name
+		source[kind] = orgv1alpha2.RegisterSourceDeployment
+	}
+	sort.Strings(overrides)
+	return register, source, overrides
+}
+
+// OrganizationRegister merges the default and organization registers, in
+// that order.
+func OrganizationRegister(defRegister, orgRegister map[string]string) map[string]string {
+	register, _, _ := DeploymentRegister(defRegister, orgRegister, nil)
+	return register
+}
+
+// DeploymentAddressAllocationStrategy merges the address allocation strategy
+// field by field from the defaults, the organization and the deployment, in
+// that order, and returns the source of every field.
+func DeploymentAddressAllocationStrategy(orgaas, depaas *nddov1.AddressAllocationStrategy) (*nddov1.AddressAllocationStrategy, *orgv1alpha2.NddrOrgAddressAllocationStrategySource) {
+	aas := defaults.DefaultAddressAllocationStrategy(nil)
+	source := &orgv1alpha2.NddrOrgAddressAllocationStrategySource{
+		GatewayAllocation:          utils.StringPtr(orgv1alpha2.RegisterSourceDefault),
+		InfraItfcePrefixLengthIpv4: utils.StringPtr(orgv1alpha2.RegisterSourceDefault),
+		InfraItfcePrefixLengthIpv6: utils.StringPtr(orgv1alpha2.RegisterSourceDefault),
+	}
+
+	for _, x := range []struct {
+		aas    *nddov1.AddressAllocationStrategy
+		source string
+	}{
+		{aas: orgaas, source: orgv1alpha2.RegisterSourceOrganization},
+		{aas: depaas, source: orgv1alpha2.RegisterSourceDeployment},
+	} {
+		if x.aas == nil {
+			continue
+		}
+		if x.aas.GatewayAllocation != nil {
+			gwa := *x.aas.GatewayAllocation
+			aas.GatewayAllocation = &gwa
+			source.GatewayAllocation = utils.StringPtr(x.source)
+		}
+		if x.aas.InfraItfcePrefixLengthIpv4 != nil {
+			aas.InfraItfcePrefixLengthIpv4 = utils.Uint32Ptr(*x.aas.InfraItfcePrefixLengthIpv4)
+			source.InfraItfcePrefixLengthIpv4 = utils.StringPtr(x.source)
+		}
+		if x.aas.InfraItfcePrefixLengthIpv6 != nil {
+			aas.InfraItfcePrefixLengthIpv6 = utils.Uint32Ptr(*x.aas.InfraItfcePrefixLengthIpv6)
+			source.InfraItfcePrefixLengthIpv6 = utils.StringPtr(x.source)
+		}
+	}
+	return aas, source
+}
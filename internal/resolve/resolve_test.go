@@ -0,0 +1,192 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolve
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+)
+
+func gatewayAllocationPtr(g nddov1.GatewayAllocation) *nddov1.GatewayAllocation {
+	return &g
+}
+
+func TestDeploymentAddressAllocationStrategy(t *testing.T) {
+	type want struct {
+		aas    *nddov1.AddressAllocationStrategy
+		source *orgv1alpha2.NddrOrgAddressAllocationStrategySource
+	}
+	cases := map[string]struct {
+		orgaas *nddov1.AddressAllocationStrategy
+		depaas *nddov1.AddressAllocationStrategy
+		want   want
+	}{
+		"Defaults": {
+			want: want{
+				aas: &nddov1.AddressAllocationStrategy{
+					GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationFirst),
+					InfraItfcePrefixLengthIpv4: utils.Uint32Ptr(31),
+					InfraItfcePrefixLengthIpv6: utils.Uint32Ptr(127),
+				},
+				source: &orgv1alpha2.NddrOrgAddressAllocationStrategySource{
+					GatewayAllocation:          utils.StringPtr(orgv1alpha2.RegisterSourceDefault),
+					InfraItfcePrefixLengthIpv4: utils.StringPtr(orgv1alpha2.RegisterSourceDefault),
+					InfraItfcePrefixLengthIpv6: utils.StringPtr(orgv1alpha2.RegisterSourceDefault),
+				},
+			},
+		},
+		"Organization": {
+			orgaas: &nddov1.AddressAllocationStrategy{
+				GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationLast),
+				InfraItfcePrefixLengthIpv4: utils.Uint32Ptr(30),
+			},
+			want: want{
+				aas: &nddov1.AddressAllocationStrategy{
+					GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationLast),
+					InfraItfcePrefixLengthIpv4: utils.Uint32Ptr(30),
+					InfraItfcePrefixLengthIpv6: utils.Uint32Ptr(127),
+				},
+				source: &orgv1alpha2.NddrOrgAddressAllocationStrategySource{
+					GatewayAllocation:          utils.StringPtr(orgv1alpha2.RegisterSourceOrganization),
+					InfraItfcePrefixLengthIpv4: utils.StringPtr(orgv1alpha2.RegisterSourceOrganization),
+					InfraItfcePrefixLengthIpv6: utils.StringPtr(orgv1alpha2.RegisterSourceDefault),
+				},
+			},
+		},
+		"DeploymentOverridesIpv6Only": {
+			orgaas: &nddov1.AddressAllocationStrategy{
+				GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationLast),
+				InfraItfcePrefixLengthIpv4: utils.Uint32Ptr(30),
+				InfraItfcePrefixLengthIpv6: utils.Uint32Ptr(64),
+			},
+			depaas: &nddov1.AddressAllocationStrategy{
+				InfraItfcePrefixLengthIpv6: utils.Uint32Ptr(126),
+			},
+			want: want{
+				aas: &nddov1.AddressAllocationStrategy{
+					GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationLast),
+					InfraItfcePrefixLengthIpv4: utils.Uint32Ptr(30),
+					InfraItfcePrefixLengthIpv6: utils.Uint32Ptr(126),
+				},
+				source: &orgv1alpha2.NddrOrgAddressAllocationStrategySource{
+					GatewayAllocation:          utils.StringPtr(orgv1alpha2.RegisterSourceOrganization),
+					InfraItfcePrefixLengthIpv4: utils.StringPtr(orgv1alpha2.RegisterSourceOrganization),
+					InfraItfcePrefixLengthIpv6: utils.StringPtr(orgv1alpha2.RegisterSourceDeployment),
+				},
+			},
+		},
+		"DeploymentOverridesAll": {
+			orgaas: &nddov1.AddressAllocationStrategy{
+				GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationLast),
+				InfraItfcePrefixLengthIpv4: utils.Uint32Ptr(30),
+			},
+			depaas: &nddov1.AddressAllocationStrategy{
+				GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationFirst),
+				InfraItfcePrefixLengthIpv4: utils.Uint32Ptr(31),
+				InfraItfcePrefixLengthIpv6: utils.Uint32Ptr(126),
+			},
+			want: want{
+				aas: &nddov1.AddressAllocationStrategy{
+					GatewayAllocation:          gatewayAllocationPtr(nddov1.GatewayAllocationFirst),
+					InfraItfcePrefixLengthIpv4: utils.Uint32Ptr(31),
+					InfraItfcePrefixLengthIpv6: utils.Uint32Ptr(126),
+				},
+				source: &orgv1alpha2.NddrOrgAddressAllocationStrategySource{
+					GatewayAllocation:          utils.StringPtr(orgv1alpha2.RegisterSourceDeployment),
+					InfraItfcePrefixLengthIpv4: utils.StringPtr(orgv1alpha2.RegisterSourceDeployment),
+					InfraItfcePrefixLengthIpv6: utils.StringPtr(orgv1alpha2.RegisterSourceDeployment),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			orgaas := tc.orgaas.DeepCopy()
+			aas, source := DeploymentAddressAllocationStrategy(tc.orgaas, tc.depaas)
+			if diff := cmp.Diff(tc.want.aas, aas); diff != "" {
+				t.Errorf("DeploymentAddressAllocationStrategy(...): -want aas, +got aas:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.source, source); diff != "" {
+				t.Errorf("DeploymentAddressAllocationStrategy(...): -want source, +got source:\n%s", diff)
+			}
+			if diff := cmp.Diff(orgaas, tc.orgaas); diff != "" {
+				t.Errorf("DeploymentAddressAllocationStrategy(...): organization strategy modified: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDeploymentRegister(t *testing.T) {
+	type want struct {
+		register  map[string]string
+		source    map[string]string
+		overrides []string
+	}
+	defRegister := map[string]string{"ipam": "nokia.default", "as": "nokia.default"}
+	cases := map[string]struct {
+		orgRegister map[string]string
+		depRegister map[string]string
+		want        want
+	}{
+		"Defaults": {
+			want: want{
+				register:  map[string]string{"ipam": "nokia.default", "as": "nokia.default"},
+				source:    map[string]string{"ipam": orgv1alpha2.RegisterSourceDefault, "as": orgv1alpha2.RegisterSourceDefault},
+				overrides: []string{},
+			},
+		},
+		"Organization": {
+			orgRegister: map[string]string{"ipam": "nokia.ipam"},
+			want: want{
+				register:  map[string]string{"ipam": "nokia.ipam", "as": "nokia.default"},
+				source:    map[string]string{"ipam": orgv1alpha2.RegisterSourceOrganization, "as": orgv1alpha2.RegisterSourceDefault},
+				overrides: []string{},
+			},
+		},
+		"DeploymentSetsDefault": {
+			depRegister: map[string]string{"as": "nokia.default"},
+			want: want{
+				register:  map[string]string{"ipam": "nokia.default", "as": "nokia.default"},
+				source:    map[string]string{"ipam": orgv1alpha2.RegisterSourceDefault, "as": orgv1alpha2.RegisterSourceDeployment},
+				overrides: []string{},
+			},
+		},
+		"DeploymentOverridesOrganization": {
+			orgRegister: map[string]string{"ipam": "nokia.ipam"},
+			depRegister: map[string]string{"ipam": "nokia.region1"},
+			want: want{
+				register:  map[string]string{"ipam": "nokia.region1", "as": "nokia.default"},
+				source:    map[string]string{"ipam": orgv1alpha2.RegisterSourceDeployment, "as": orgv1alpha2.RegisterSourceDefault},
+				overrides: []string{"ipam"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			register, source, overrides := DeploymentRegister(defRegister, tc.orgRegister, tc.depRegister)
+			if diff := cmp.Diff(tc.want, want{register: register, source: source, overrides: overrides}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("DeploymentRegister(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -19,10 +19,43 @@ import (
 	"time"
 
 	"github.com/yndd/ndd-runtime/pkg/logging"
+	"github.com/yndd/nddr-organization/internal/requeue"
+	"github.com/yndd/nddr-organization/pkg/registry"
 )
 
 type NddControllerOptions struct {
 	Logger    logging.Logger
 	Poll      time.Duration
 	Namespace string
+	// organization defaults
+	RegisterKinds        []string
+	RegisterNameTemplate string
+	// registry backend credentials
+	RegistryCredentials *registry.Credentials
+	// ClusterOrganizationNamespace is the designated namespace of the
+	// organizations that govern the deployments of all namespaces; it stands
+	// in for cluster-scoped organizations, which the namespaced organization
+	// CRD does not support. When empty an organization only governs the
+	// deployments in its own namespace
+	ClusterOrganizationNamespace string
+	// requeue policy of the reconciled resources
+	Requeue requeue.Options
+}
+
+// OrganizationNamespace returns the namespace of the organizations that
+// govern the deployments in the namespace.
+func (o *NddControllerOptions) OrganizationNamespace(namespace string) string {
+	if o.ClusterOrganizationNamespace != "" {
+		return o.ClusterOrganizationNamespace
+	}
+	return namespace
+}
+
+// DeploymentNamespace returns the namespace of the deployments that may be
+// governed by the organizations in the namespace; empty for all namespaces.
+func (o *NddControllerOptions) DeploymentNamespace(namespace string) string {
+	if o.ClusterOrganizationNamespace != "" && o.ClusterOrganizationNamespace == namespace {
+		return ""
+	}
+	return namespace
 }
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var suppressedWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "nddr",
+	Subsystem: "status",
+	Name:      "writes_suppressed_total",
+	Help:      "Number of status writes skipped because the status did not change, by controller.",
+}, []string{"controller"})
+
+func init() {
+	metrics.Registry.MustRegister(suppressedWrites)
+}
+
+// NewManager returns a manager whose client skips the status updates that
+// do not change the status of the object as it is known to the cache. The
+// controller name labels the suppressed writes metric.
+func NewManager(mgr ctrl.Manager, controller string) ctrl.Manager {
+	return &manager{
+		Manager: mgr,
+		client: &statusClient{
+			Client:     mgr.GetClient(),
+			controller: controller,
+		},
+	}
+}
+
+type manager struct {
+	ctrl.Manager
+	client client.Client
+}
+
+func (m *manager) GetClient() client.Client {
+	return m.client
+}
+
+type statusClient struct {
+	client.Client
+	controller string
+}
+
+func (c *statusClient) Status() client.StatusWriter {
+	return &statusWriter{
+		StatusWriter: c.Client.Status(),
+		reader:       c.Client,
+		controller:   c.controller,
+	}
+}
+
+type statusWriter struct {
+	client.StatusWriter
+	reader     client.Reader
+	controller string
+}
+
+// Update updates the status of the object unless it is semantically equal
+// to the status of the current object.
+func (w *statusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if w.unchanged(ctx, obj) {
+		suppressedWrites.WithLabelValues(w.controller).Inc()
+		return nil
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+// unchanged returns true if the status of the object equals the status of
+// the current object; any failure to compare results in a write.
+func (w *statusWriter) unchanged(ctx context.Context, obj client.Object) bool {
+	current, ok := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(client.Object)
+	if !ok {
+		return false
+	}
+	if err := w.reader.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, current); err != nil {
+		return false
+	}
+	if current.GetResourceVersion() != obj.GetResourceVersion() {
+		return false
+	}
+	desired, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return false
+	}
+	observed, err := runtime.DefaultUnstructuredConverter.ToUnstructured(current)
+	if err != nil {
+		return false
+	}
+	return equality.Semantic.DeepEqual(desired["status"], observed["status"])
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// recorder records the status updates that are written
+type recorder struct {
+	client.StatusWriter
+	writes int
+}
+
+func (r *recorder) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	r.writes++
+	return nil
+}
+
+func TestStatusWriterUpdate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	key := types.NamespacedName{Namespace: "default", Name: "nokia"}
+
+	cases := map[string]struct {
+		objects   []client.Object
+		mutate    func(org *orgv1alpha2.Organization)
+		wantWrite bool
+	}{
+		"Unchanged": {
+			objects: []client.Object{&orgv1alpha2.Organization{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}},
+			mutate:  func(org *orgv1alpha2.Organization) {},
+		},
+		"StatusChanged": {
+			objects: []client.Object{&orgv1alpha2.Organization{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}},
+			mutate: func(org *orgv1alpha2.Organization) {
+				org.SetConditions(orgv1alpha2.Ready())
+			},
+			wantWrite: true,
+		},
+		"StaleResourceVersion": {
+			objects: []client.Object{&orgv1alpha2.Organization{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}},
+			mutate: func(org *orgv1alpha2.Organization) {
+				org.SetResourceVersion("1")
+			},
+			wantWrite: true,
+		},
+		"NotFound": {
+			mutate: func(org *orgv1alpha2.Organization) {
+				org.SetName(key.Name)
+				org.SetNamespace(key.Namespace)
+			},
+			wantWrite: true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			org := &orgv1alpha2.Organization{}
+			if len(tc.objects) > 0 {
+				if err := c.Get(context.Background(), key, org); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tc.mutate(org)
+
+			r := &recorder{}
+			w := &statusWriter{StatusWriter: r, reader: c, controller: name}
+			if err := w.Update(context.Background(), org); err != nil {
+				t.Fatal(err)
+			}
+			if got := r.writes == 1; got != tc.wantWrite {
+				t.Errorf("Update(...): want write %t, got %t", tc.wantWrite, got)
+			}
+			wantSuppressed := 1.0
+			if tc.wantWrite {
+				wantSuppressed = 0
+			}
+			if got := testutil.ToFloat64(suppressedWrites.WithLabelValues(name)); got != wantSuppressed {
+				t.Errorf("Update(...): want %v suppressed writes, got %v", wantSuppressed, got)
+			}
+		})
+	}
+}
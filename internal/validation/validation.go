@@ -0,0 +1,234 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"strings"
+
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/resolve"
+	"github.com/yndd/nddr-organization/pkg/registry"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var (
+	// AdminStates are the supported admin states
+	AdminStates = []string{"disable", "enable"}
+	// DeploymentKinds are the supported deployment kinds
+	DeploymentKinds = []string{"dc", "wan"}
+	// DeletionPolicies are the supported organization deletion policies
+	DeletionPolicies = []string{orgv1alpha2.DeletionPolicyBlock, orgv1alpha2.DeletionPolicyCascade}
+)
+
+// ValidateOrganizationAdmission validates the organization as the admission
+// webhook does. The errors reject the organization; the warnings, i.e. the
+// missing critical registers, do not since the controller reports them as a
+// condition. The warnings are only evaluated for an organization without
+// errors, whose register entries are complete.
+func ValidateOrganizationAdmission(cr *orgv1alpha2.Organization) (field.ErrorList, field.ErrorList) {
+	if errs := ValidateOrganization(cr); len(errs) > 0 {
+		return errs, nil
+	}
+	return nil, ValidateOrganizationCriticalRegisters(cr)
+}
+
+// ValidateDeploymentAdmission validates the deployment as the admission
+// webhook does; org is nil when it was not found. The errors reject the
+// deployment; the warnings, i.e. the missing critical registers, do not since
+// the controller reports them as a condition.
+func ValidateDeploymentAdmission(cr *orgv1alpha2.Deployment, org *orgv1alpha2.Organization, o *defaults.Options) (field.ErrorList, field.ErrorList) {
+	if errs := ValidateDeployment(cr); len(errs) > 0 {
+		return errs, nil
+	}
+	return ValidateDeploymentOrganization(cr, org), ValidateDeploymentCriticalRegisters(cr, org, o)
+}
+
+// ValidateOrganization validates the organization spec. The organization name
+// is the first segment of every deployment and register name and cannot
+// contain a dot.
+func ValidateOrganization(cr *orgv1alpha2.Organization) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if strings.Contains(cr.GetName(), ".") {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), cr.GetName(), "organization name cannot contain a dot"))
+	}
+
+	if cr.Spec.Organization == nil {
+		return allErrs
+	}
+	fldPath := field.NewPath("spec", "organization")
+	allErrs = append(allErrs, validateEnum(cr.Spec.Organization.AdminState, AdminStates, fldPath.Child("admin-state"))...)
+	allErrs = append(allErrs, validateEnum(cr.Spec.Organization.DeletionPolicy, DeletionPolicies, fldPath.Child("deletion-policy"))...)
+	allErrs = append(allErrs, ValidateRegister(cr.Spec.Organization.Register, fldPath.Child("register"))...)
+	allErrs = append(allErrs, ValidateRequiredRegisters(cr.Spec.Organization.RequiredRegisters, fldPath.Child("required-registers"))...)
+
+	deploymentKinds := make(map[string]struct{})
+	for i, r := range cr.Spec.Organization.DeploymentRequiredRegisters {
+		idxPath := fldPath.Child("deployment-required-registers").Index(i)
+		if r == nil {
+			continue
+		}
+		if r.DeploymentKind == nil || *r.DeploymentKind == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("deployment-kind"), ""))
+		} else {
+			allErrs = append(allErrs, validateEnum(r.DeploymentKind, DeploymentKinds, idxPath.Child("deployment-kind"))...)
+			if _, ok := deploymentKinds[*r.DeploymentKind]; ok {
+				allErrs = append(allErrs, field.Duplicate(idxPath.Child("deployment-kind"), *r.DeploymentKind))
+			}
+			deploymentKinds[*r.DeploymentKind] = struct{}{}
+		}
+		allErrs = append(allErrs, ValidateRequiredRegisters(r.RequiredRegisters, idxPath.Child("required-registers"))...)
+	}
+	return allErrs
+}
+
+// ValidateDeployment validates the deployment spec. The deployment name
+// must be structured as <organization-ref>.<deployment>.
+func ValidateDeployment(cr *orgv1alpha2.Deployment) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cr.Spec.Deployment == nil {
+		return append(allErrs, field.Required(field.NewPath("spec", "deployment"), ""))
+	}
+
+	orgName := cr.GetOrganizationName()
+	if orgName == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "deployment", "organization-ref"), ""))
+	} else if !strings.HasPrefix(cr.GetName(), orgName+".") || cr.GetDeploymentName() == "" {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), cr.GetName(), "deployment name must be structured as <organization-ref>.<deployment>"))
+	}
+
+	allErrs = append(allErrs, validateEnum(cr.Spec.Deployment.AdminState, AdminStates, field.NewPath("spec", "deployment", "admin-state"))...)
+	allErrs = append(allErrs, validateEnum(cr.Spec.Deployment.Kind, DeploymentKinds, field.NewPath("spec", "deployment", "kind"))...)
+	allErrs = append(allErrs, ValidateRegister(cr.Spec.Deployment.Register, field.NewPath("spec", "deployment", "register"))...)
+	return allErrs
+}
+
+// ValidateDeploymentOrganization validates that the organization the
+// deployment references exists; org is nil when it was not found.
+func ValidateDeploymentOrganization(cr *orgv1alpha2.Deployment, org *orgv1alpha2.Organization) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if cr.GetOrganizationName() != "" && org == nil {
+		allErrs = append(allErrs, field.NotFound(field.NewPath("spec", "deployment", "organization-ref"), cr.GetOrganizationName()))
+	}
+	return allErrs
+}
+
+// ValidateOrganizationCriticalRegisters validates that the register of an
+// enabled organization has the registers it requires, as enforced by
+// registry.GetRegister. The register is validated after defaulting.
+func ValidateOrganizationCriticalRegisters(cr *orgv1alpha2.Organization) field.ErrorList {
+	register := make(map[string]string)
+	var required []string
+	if cr.Spec.Organization != nil {
+		if cr.GetAdminState() == "disable" {
+			return nil
+		}
+		register = cr.GetRegister()
+		required = cr.GetRequiredRegisters()
+	}
+	return criticalRegisterErrors(registry.MissingRegisters(register, registry.RequiredRegisters(required)), field.NewPath("spec", "organization", "register"))
+}
+
+// ValidateDeploymentCriticalRegisters validates that the effective register
+// of an enabled deployment has the registers its organization requires for
+// its kind, as enforced by registry.GetRegister; org is nil when it was not
+// found.
+func ValidateDeploymentCriticalRegisters(cr *orgv1alpha2.Deployment, org *orgv1alpha2.Organization, o *defaults.Options) field.ErrorList {
+	if org == nil || cr.Spec.Deployment == nil || org.Spec.Organization == nil {
+		return nil
+	}
+	d := resolve.ResolveDeployment(cr, org, o)
+	if d.Status != resolve.StatusUp {
+		return nil
+	}
+	return criticalRegisterErrors(d.MissingRegisters, field.NewPath("spec", "deployment", "register"))
+}
+
+func criticalRegisterErrors(missing []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, kind := range missing {
+		allErrs = append(allErrs, field.Required(fldPath.Key(kind), "critical register is missing"))
+	}
+	return allErrs
+}
+
+// ValidateRegister validates that every register entry has a known kind and
+// a name, and that each kind is registered only once.
+func ValidateRegister(registers []*nddov1.Register, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	kinds := make(map[string]struct{})
+	for i, register := range registers {
+		idxPath := fldPath.Index(i)
+		if register == nil {
+			allErrs = append(allErrs, field.Required(idxPath, ""))
+			continue
+		}
+		if register.Name == nil || *register.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+		}
+		if register.Kind == nil || *register.Kind == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("kind"), ""))
+			continue
+		}
+		kind := *register.Kind
+		if !registry.IsRegisterKind(kind) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("kind"), kind, registerKinds()))
+		}
+		if _, ok := kinds[kind]; ok {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("kind"), kind))
+		}
+		kinds[kind] = struct{}{}
+	}
+	return allErrs
+}
+
+// ValidateRequiredRegisters validates that the required registers are known
+// register kinds.
+func ValidateRequiredRegisters(required []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, kind := range required {
+		if !registry.IsRegisterKind(kind) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), kind, registerKinds()))
+		}
+	}
+	return allErrs
+}
+
+func validateEnum(value *string, allowed []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if value == nil {
+		return allErrs
+	}
+	for _, a := range allowed {
+		if *value == a {
+			return allErrs
+		}
+	}
+	return append(allErrs, field.NotSupported(fldPath, *value, allowed))
+}
+
+func registerKinds() []string {
+	kinds := make([]string, 0, len(registry.RegisterKinds))
+	for _, kind := range registry.RegisterKinds {
+		kinds = append(kinds, kind.String())
+	}
+	return kinds
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func register(kind, name string) *nddov1.Register {
+	return &nddov1.Register{Kind: utils.StringPtr(kind), Name: utils.StringPtr(name)}
+}
+
+func organization(name string, spec *orgv1alpha2.OrgOrganization) *orgv1alpha2.Organization {
+	return &orgv1alpha2.Organization{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       orgv1alpha2.OrganizationSpec{Organization: spec},
+	}
+}
+
+func deployment(name string, spec *orgv1alpha2.OrgDeployment) *orgv1alpha2.Deployment {
+	return &orgv1alpha2.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       orgv1alpha2.DeploymentSpec{Deployment: spec},
+	}
+}
+
+// findings returns the type and field of the errors
+func findings(errs field.ErrorList) []string {
+	f := make([]string, 0, len(errs))
+	for _, err := range errs {
+		f = append(f, fmt.Sprintf("%s %s", string(err.Type), err.Field))
+	}
+	return f
+}
+
+func TestValidateOrganizationAdmission(t *testing.T) {
+	complete := []*nddov1.Register{register("ipam", "nokia.default"), register("as", "nokia.default")}
+
+	type want struct {
+		errs     []string
+		warnings []string
+	}
+	cases := map[string]struct {
+		org  *orgv1alpha2.Organization
+		want want
+	}{
+		"Valid": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{Register: complete}),
+		},
+		"NameWithDot": {
+			org: organization("nokia.be", &orgv1alpha2.OrgOrganization{Register: complete}),
+			want: want{
+				errs: []string{"FieldValueInvalid metadata.name"},
+			},
+		},
+		"UnknownAdminState": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{AdminState: utils.StringPtr("up"), Register: complete}),
+			want: want{
+				errs: []string{"FieldValueNotSupported spec.organization.admin-state"},
+			},
+		},
+		"UnknownDeletionPolicy": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{DeletionPolicy: utils.StringPtr("orphan"), Register: complete}),
+			want: want{
+				errs: []string{"FieldValueNotSupported spec.organization.deletion-policy"},
+			},
+		},
+		"InvalidRegister": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{Register: []*nddov1.Register{
+				complete[0], complete[1],
+				register("ipam", "nokia.other"),
+				register("unknown", "nokia.default"),
+				{Kind: utils.StringPtr("vlan")},
+			}}),
+			want: want{
+				errs: []string{
+					"FieldValueDuplicate spec.organization.register[2].kind",
+					"FieldValueNotSupported spec.organization.register[3].kind",
+					"FieldValueRequired spec.organization.register[4].name",
+				},
+			},
+		},
+		"UnknownRequiredRegister": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{Register: complete, RequiredRegisters: []string{"unknown"}}),
+			want: want{
+				errs: []string{"FieldValueNotSupported spec.organization.required-registers[0]"},
+			},
+		},
+		"InvalidDeploymentRequiredRegisters": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{Register: complete, DeploymentRequiredRegisters: []*orgv1alpha2.OrgDeploymentRequiredRegisters{
+				{DeploymentKind: utils.StringPtr("dc")},
+				{DeploymentKind: utils.StringPtr("dc")},
+				{DeploymentKind: utils.StringPtr("lan")},
+				{RequiredRegisters: []string{"unknown"}},
+			}}),
+			want: want{
+				errs: []string{
+					"FieldValueDuplicate spec.organization.deployment-required-registers[1].deployment-kind",
+					"FieldValueNotSupported spec.organization.deployment-required-registers[2].deployment-kind",
+					"FieldValueRequired spec.organization.deployment-required-registers[3].deployment-kind",
+					"FieldValueNotSupported spec.organization.deployment-required-registers[3].required-registers[0]",
+				},
+			},
+		},
+		"MissingCriticalRegisters": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{Register: complete[:1]}),
+			want: want{
+				warnings: []string{"FieldValueRequired spec.organization.register[as]"},
+			},
+		},
+		"MissingRequiredRegisters": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{Register: complete, RequiredRegisters: []string{"ipam", "vlan"}}),
+			want: want{
+				warnings: []string{"FieldValueRequired spec.organization.register[vlan]"},
+			},
+		},
+		"DisabledWithoutCriticalRegisters": {
+			org: organization("nokia", &orgv1alpha2.OrgOrganization{AdminState: utils.StringPtr("disable")}),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			errs, warnings := ValidateOrganizationAdmission(tc.org)
+			if diff := cmp.Diff(tc.want.errs, findings(errs), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("ValidateOrganizationAdmission(...): errors -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.warnings, findings(warnings), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("ValidateOrganizationAdmission(...): warnings -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateDeploymentAdmission(t *testing.T) {
+	o := &defaults.Options{RegisterNameTemplate: defaults.DefaultRegisterNameTemplate}
+	org := organization("nokia", &orgv1alpha2.OrgOrganization{
+		AdminState: utils.StringPtr("enable"),
+		Register:   []*nddov1.Register{register("ipam", "nokia.default"), register("as", "nokia.default")},
+		DeploymentRequiredRegisters: []*orgv1alpha2.OrgDeploymentRequiredRegisters{
+			{DeploymentKind: utils.StringPtr("wan"), RequiredRegisters: []string{"ipam", "as", "vlan"}},
+		},
+	})
+
+	type want struct {
+		errs     []string
+		warnings []string
+	}
+	cases := map[string]struct {
+		dep  *orgv1alpha2.Deployment
+		org  *orgv1alpha2.Organization
+		want want
+	}{
+		"Valid": {
+			dep: deployment("nokia.region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia"), Kind: utils.StringPtr("dc")}),
+			org: org,
+		},
+		"MissingSpec": {
+			dep: deployment("nokia.region1", nil),
+			org: org,
+			want: want{
+				errs: []string{"FieldValueRequired spec.deployment"},
+			},
+		},
+		"MissingOrganizationRef": {
+			dep: deployment("nokia.region1", &orgv1alpha2.OrgDeployment{}),
+			org: org,
+			want: want{
+				errs: []string{"FieldValueRequired spec.deployment.organization-ref"},
+			},
+		},
+		"NameWithoutOrganization": {
+			dep: deployment("region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia")}),
+			org: org,
+			want: want{
+				errs: []string{"FieldValueInvalid metadata.name"},
+			},
+		},
+		"NameWithoutDeployment": {
+			dep: deployment("nokia.", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia")}),
+			org: org,
+			want: want{
+				errs: []string{"FieldValueInvalid metadata.name"},
+			},
+		},
+		"UnknownKindAndAdminState": {
+			dep: deployment("nokia.region1", &orgv1alpha2.OrgDeployment{
+				OrganizationRef: utils.StringPtr("nokia"),
+				Kind:            utils.StringPtr("lan"),
+				AdminState:      utils.StringPtr("up"),
+			}),
+			org: org,
+			want: want{
+				errs: []string{
+					"FieldValueNotSupported spec.deployment.admin-state",
+					"FieldValueNotSupported spec.deployment.kind",
+				},
+			},
+		},
+		"InvalidRegister": {
+			dep: deployment("nokia.region1", &orgv1alpha2.OrgDeployment{
+				OrganizationRef: utils.StringPtr("nokia"),
+				Register:        []*nddov1.Register{register("unknown", "nokia.region1")},
+			}),
+			org: org,
+			want: want{
+				errs: []string{"FieldValueNotSupported spec.deployment.register[0].kind"},
+			},
+		},
+		"OrganizationNotFound": {
+			dep: deployment("nokia.region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia")}),
+			want: want{
+				errs: []string{"FieldValueNotFound spec.deployment.organization-ref"},
+			},
+		},
+		"MissingCriticalRegisters": {
+			dep: deployment("nokia.region1", &orgv1alpha2.OrgDeployment{OrganizationRef: utils.StringPtr("nokia"), Kind: utils.StringPtr("wan")}),
+			org: org,
+			want: want{
+				warnings: []string{"FieldValueRequired spec.deployment.register[vlan]"},
+			},
+		},
+		"DeploymentRegistersCriticalRegister": {
+			dep: deployment("nokia.region1", &orgv1alpha2.OrgDeployment{
+				OrganizationRef: utils.StringPtr("nokia"),
+				Kind:            utils.StringPtr("wan"),
+				Register:        []*nddov1.Register{register("vlan", "nokia.region1")},
+			}),
+			org: org,
+		},
+		"DisabledWithoutCriticalRegisters": {
+			dep: deployment("nokia.region1", &orgv1alpha2.OrgDeployment{
+				OrganizationRef: utils.StringPtr("nokia"),
+				Kind:            utils.StringPtr("wan"),
+				AdminState:      utils.StringPtr("disable"),
+			}),
+			org: org,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			errs, warnings := ValidateDeploymentAdmission(tc.dep, tc.org, o)
+			if diff := cmp.Diff(tc.want.errs, findings(errs), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("ValidateDeploymentAdmission(...): errors -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.warnings, findings(warnings), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("ValidateDeploymentAdmission(...): warnings -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	// certValidity is the validity of the generated ca and serving certificate
+	certValidity = 10 * 365 * 24 * time.Hour
+	// certRenewBefore renews the serving certificate when it expires within
+	// this period
+	certRenewBefore = 30 * 24 * time.Hour
+)
+
+// secretKeyCA is the key of the ca certificate in the certificate secret, as
+// used by cert-manager
+const secretKeyCA = "ca.crt"
+
+// certificate is a PEM encoded self-signed ca with a serving certificate and
+// key signed by it.
+type certificate struct {
+	caCert []byte
+	cert   []byte
+	key    []byte
+}
+
+// newCertificate generates a ca and a serving certificate for the dns names.
+func newCertificate(dnsNames []string, now time.Time) (*certificate, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caSerial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          caSerial,
+		Subject:               pkix.Name{CommonName: dnsNames[0] + "-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(certValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certificate{
+		caCert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		cert:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:    pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// valid returns nil when the serving certificate is signed by the ca, serves
+// all dns names and does not expire within certRenewBefore.
+func (c *certificate) valid(dnsNames []string, now time.Time) error {
+	if len(c.caCert) == 0 || len(c.cert) == 0 || len(c.key) == 0 {
+		return errors.New("incomplete certificate")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(c.caCert) {
+		return errors.New("invalid ca certificate")
+	}
+	block, _ := pem.Decode(c.cert)
+	if block == nil {
+		return errors.New("invalid serving certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+	if now.Add(certRenewBefore).After(cert.NotAfter) {
+		return fmt.Errorf("certificate expires at %s", cert.NotAfter)
+	}
+	for _, dnsName := range dnsNames {
+		if _, err := cert.Verify(x509.VerifyOptions{
+			DNSName:     dnsName,
+			Roots:       pool,
+			CurrentTime: now,
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		}); err != nil {
+			return err
+		}
+	}
+	keyBlock, _ := pem.Decode(c.key)
+	if keyBlock == nil {
+		return errors.New("invalid serving key")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return err
+	}
+	pub, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !pub.Equal(&key.PublicKey) {
+		return errors.New("serving key does not match the certificate")
+	}
+	return nil
+}
+
+// serialNumber returns a random 128 bit serial number.
+func serialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yndd/ndd-runtime/pkg/logging"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/shared"
+	"github.com/yndd/nddr-organization/internal/validation"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	validateDeploymentPath = "/validate-org-nddr-yndd-io-v1alpha2-deployment"
+)
+
+func setupDeployment(mgr ctrl.Manager, nddcopts *shared.NddControllerOptions) error {
+	if err := (&orgv1alpha2.Deployment{}).SetupWebhookWithManager(mgr); err != nil {
+		return err
+	}
+
+	mgr.GetWebhookServer().Register(validateDeploymentPath, &webhook.Admission{
+		Handler: &deploymentValidator{
+			client:       mgr.GetClient(),
+			log:          nddcopts.Logger.WithValues("webhook", validateDeploymentPath),
+			orgNamespace: nddcopts.OrganizationNamespace,
+			opts: &defaults.Options{
+				RegisterKinds:        nddcopts.RegisterKinds,
+				RegisterNameTemplate: nddcopts.RegisterNameTemplate,
+			},
+		},
+	})
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-org-nddr-yndd-io-v1alpha2-deployment,mutating=false,failurePolicy=fail,sideEffects=None,groups=org.nddr.yndd.io,resources=deployments,verbs=create;update,versions=v1alpha2,name=vdeployment.org.nddr.yndd.io,admissionReviewVersions={v1,v1beta1}
+
+type deploymentValidator struct {
+	client client.Client
+	log    logging.Logger
+	opts   *defaults.Options
+	// orgNamespace returns the namespace of the organization of a deployment
+	orgNamespace func(string) string
+	decoder      *admission.Decoder
+}
+
+// InjectDecoder injects the decoder.
+func (v *deploymentValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle validates a deployment create or update request.
+func (v *deploymentValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cr := &orgv1alpha2.Deployment{}
+	if err := v.decoder.Decode(req, cr); err != nil {
+		return decodeErrored(err)
+	}
+	log := v.log.WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	// a deployment that is deleted or whose spec did not change is not
+	// validated again, such that its finalizer can be removed when its
+	// organization is gone
+	if req.Operation == admissionv1.Update {
+		if cr.GetDeletionTimestamp() != nil {
+			return admission.Allowed("")
+		}
+		old := &orgv1alpha2.Deployment{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return decodeErrored(err)
+		}
+		if equality.Semantic.DeepEqual(old.Spec, cr.Spec) {
+			return admission.Allowed("")
+		}
+	}
+
+	var org *orgv1alpha2.Organization
+	if cr.GetOrganizationName() != "" {
+		org = &orgv1alpha2.Organization{}
+		if err := v.client.Get(ctx, types.NamespacedName{
+			Namespace: v.orgNamespace(cr.GetNamespace()),
+			Name:      cr.GetOrganizationName(),
+		}, org); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return admission.Errored(http.StatusInternalServerError, err)
+			}
+			org = nil
+		}
+	}
+	errs, warnings := validation.ValidateDeploymentAdmission(cr, org, v.opts)
+	if len(errs) > 0 {
+		log.Debug("deployment rejected", "error", errs.ToAggregate())
+	}
+	return validationResponse(orgv1alpha2.DeploymentGroupVersionKind.GroupKind(), cr.GetName(), errs, warnings)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/yndd/ndd-runtime/pkg/logging"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func deployment(description string, deleted bool) *orgv1alpha2.Deployment {
+	d := &orgv1alpha2.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: orgv1alpha2.GroupVersion.String(),
+			Kind:       orgv1alpha2.DeploymentKindKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "nokia.region1", Namespace: "default"},
+		Spec: orgv1alpha2.DeploymentSpec{
+			Deployment: &orgv1alpha2.OrgDeployment{
+				OrganizationRef: utils.StringPtr("nokia"),
+				Description:     utils.StringPtr(description),
+				Kind:            utils.StringPtr("dc"),
+				AdminState:      utils.StringPtr("enable"),
+			},
+		},
+	}
+	if deleted {
+		now := metav1.Now()
+		d.SetDeletionTimestamp(&now)
+		d.SetFinalizers([]string{orgv1alpha2.DeploymentFinalizer})
+	}
+	return d
+}
+
+func raw(t *testing.T, o runtime.Object) runtime.RawExtension {
+	t.Helper()
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtime.RawExtension{Raw: b}
+}
+
+func TestDeploymentValidatorWithoutOrganization(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		operation admissionv1.Operation
+		old       *orgv1alpha2.Deployment
+		new       *orgv1alpha2.Deployment
+		allowed   bool
+	}{
+		"Create": {
+			operation: admissionv1.Create,
+			new:       deployment("region1", false),
+			allowed:   false,
+		},
+		"UpdateSpec": {
+			operation: admissionv1.Update,
+			old:       deployment("region1", false),
+			new:       deployment("region1 updated", false),
+			allowed:   false,
+		},
+		"UpdateUnchangedSpec": {
+			operation: admissionv1.Update,
+			old:       deployment("region1", false),
+			new:       deployment("region1", false),
+			allowed:   true,
+		},
+		"RemoveFinalizer": {
+			operation: admissionv1.Update,
+			old:       deployment("region1", true),
+			new: func() *orgv1alpha2.Deployment {
+				d := deployment("region1", true)
+				d.SetFinalizers(nil)
+				return d
+			}(),
+			allowed: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := &deploymentValidator{
+				// the organization of the deployment does not exist
+				client:       fake.NewClientBuilder().WithScheme(scheme).Build(),
+				log:          logging.NewNopLogger(),
+				opts:         &defaults.Options{},
+				orgNamespace: func(ns string) string { return ns },
+				decoder:      decoder,
+			}
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: tc.operation,
+				Object:    raw(t, tc.new),
+			}}
+			if tc.old != nil {
+				req.OldObject = raw(t, tc.old)
+			}
+			rsp := v.Handle(context.Background(), req)
+			if rsp.Allowed != tc.allowed {
+				t.Errorf("Handle(...): allowed %t, want %t: %v", rsp.Allowed, tc.allowed, rsp.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/yndd/ndd-runtime/pkg/logging"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/defaults"
+	"github.com/yndd/nddr-organization/internal/shared"
+	"github.com/yndd/nddr-organization/internal/validation"
+	"github.com/yndd/nddr-organization/pkg/registry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	defaultOrganizationPath  = "/mutate-org-nddr-yndd-io-v1alpha2-organization"
+	validateOrganizationPath = "/validate-org-nddr-yndd-io-v1alpha2-organization"
+)
+
+func setupOrganization(mgr ctrl.Manager, nddcopts *shared.NddControllerOptions) error {
+	if err := (&orgv1alpha2.Organization{}).SetupWebhookWithManager(mgr); err != nil {
+		return err
+	}
+
+	for _, kind := range nddcopts.RegisterKinds {
+		if !registry.IsRegisterKind(kind) {
+			return fmt.Errorf("unknown default register kind %s", kind)
+		}
+	}
+	mgr.GetWebhookServer().Register(defaultOrganizationPath, &webhook.Admission{
+		Handler: &organizationDefaulter{
+			log: nddcopts.Logger.WithValues("webhook", defaultOrganizationPath),
+			opts: &defaults.Options{
+				RegisterKinds:        nddcopts.RegisterKinds,
+				RegisterNameTemplate: nddcopts.RegisterNameTemplate,
+			},
+		},
+	})
+	mgr.GetWebhookServer().Register(validateOrganizationPath, &webhook.Admission{
+		Handler: &organizationValidator{
+			log: nddcopts.Logger.WithValues("webhook", validateOrganizationPath),
+		},
+	})
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-org-nddr-yndd-io-v1alpha2-organization,mutating=true,failurePolicy=fail,sideEffects=None,groups=org.nddr.yndd.io,resources=organizations,verbs=create;update,versions=v1alpha2,name=morganization.org.nddr.yndd.io,admissionReviewVersions={v1,v1beta1}
+
+type organizationDefaulter struct {
+	log     logging.Logger
+	opts    *defaults.Options
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder.
+func (d *organizationDefaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle fills in the missing registers and address allocation strategy of an
+// organization create or update request.
+func (d *organizationDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cr := &orgv1alpha2.Organization{}
+	if err := d.decoder.Decode(req, cr); err != nil {
+		return decodeErrored(err)
+	}
+
+	defaults.DefaultOrganization(cr, d.opts)
+
+	marshaled, err := json.Marshal(cr)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+//+kubebuilder:webhook:path=/validate-org-nddr-yndd-io-v1alpha2-organization,mutating=false,failurePolicy=fail,sideEffects=None,groups=org.nddr.yndd.io,resources=organizations,verbs=create;update,versions=v1alpha2,name=vorganization.org.nddr.yndd.io,admissionReviewVersions={v1,v1beta1}
+
+type organizationValidator struct {
+	log     logging.Logger
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder.
+func (v *organizationValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle validates an organization create or update request.
+func (v *organizationValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	cr := &orgv1alpha2.Organization{}
+	if err := v.decoder.Decode(req, cr); err != nil {
+		return decodeErrored(err)
+	}
+	log := v.log.WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	errs, warnings := validation.ValidateOrganizationAdmission(cr)
+	if len(errs) > 0 {
+		log.Debug("organization rejected", "error", errs.ToAggregate())
+	}
+	return validationResponse(orgv1alpha2.OrganizationGroupVersionKind.GroupKind(), cr.GetName(), errs, warnings)
+}
@@ -0,0 +1,428 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed" // embeds the generated webhook configurations
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yndd/ndd-runtime/pkg/logging"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DefaultServiceName is the default name of the webhook service, the
+	// certificate secret and the webhook configurations.
+	DefaultServiceName = "nddr-organization-webhook"
+	// DefaultCertDir is the default directory the serving certificate is
+	// written to, as read by the webhook server.
+	DefaultCertDir = "/tmp/k8s-webhook-server/serving-certs"
+
+	// conversionPath is the path of the conversion webhook registered by the
+	// webhook builder
+	conversionPath = "/convert"
+	// servicePort is the port of the webhook service
+	servicePort = 443
+	// labelPodTemplateHash is the pod label that differs per replica set
+	labelPodTemplateHash = "pod-template-hash"
+	// provisionAttempts bounds the retries when another replica provisions
+	// the certificate at the same time
+	provisionAttempts = 3
+)
+
+// manifests are the webhook configurations generated from the kubebuilder
+// markers, see the generate target of the Makefile.
+//
+//go:embed manifests.yaml
+var manifests []byte
+
+var (
+	// conversionCRDs are the crds served in several versions, converted by
+	// the conversion webhook
+	conversionCRDs = []string{
+		"organizations.org.nddr.yndd.io",
+		"deployments.org.nddr.yndd.io",
+	}
+	crdGroupVersionKind = schema.GroupVersionKind{
+		Group:   "apiextensions.k8s.io",
+		Version: "v1",
+		Kind:    "CustomResourceDefinition",
+	}
+)
+
+// ProvisionOptions configure the provisioning of the webhooks.
+type ProvisionOptions struct {
+	// Namespace of the manager pod and the webhook service
+	Namespace string
+	// PodName of the manager pod, the webhook service selects the pods with
+	// its labels
+	PodName string
+	// ServiceName is the name of the webhook service, the certificate secret
+	// and the webhook configurations
+	ServiceName string
+	// Port the webhook server listens on
+	Port int
+	// CertDir the serving certificate is written to
+	CertDir string
+}
+
+// A Provisioner makes the webhooks of the manager reachable by the api
+// server: it creates the webhook service, a self-signed serving certificate
+// and the webhook configurations, and injects the ca into the webhook
+// configurations and the conversion webhook of the crds. The manager needs
+// the rbac to create and update services and secrets in its namespace,
+// mutating and validating webhook configurations and to patch the crds of
+// this package, which the package manager does not grant by default.
+type Provisioner struct {
+	client client.Client
+	log    logging.Logger
+	o      ProvisionOptions
+
+	caBundle []byte
+}
+
+// NewProvisioner returns a provisioner for the webhooks of the manager. The
+// provisioner reads through an uncached client, such that it can provision
+// before the manager starts.
+func NewProvisioner(mgr ctrl.Manager, log logging.Logger, o ProvisionOptions) (*Provisioner, error) {
+	if o.Namespace == "" || o.PodName == "" {
+		return nil, errors.New("cannot provision the webhooks without the namespace and name of the manager pod")
+	}
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+	return &Provisioner{
+		client: c,
+		log:    log.WithValues("service", o.ServiceName),
+		o:      o,
+	}, nil
+}
+
+// Provision creates or updates the webhook service and the serving
+// certificate, writes the certificate to the certificate directory and
+// injects the ca. Provision must run before the webhook server starts.
+func (p *Provisioner) Provision(ctx context.Context) error {
+	if err := p.provisionService(ctx); err != nil {
+		return fmt.Errorf("cannot provision webhook service: %v", err)
+	}
+	cert, err := p.provisionCertificate(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot provision webhook certificate: %v", err)
+	}
+	if err := writeCertificate(p.o.CertDir, cert); err != nil {
+		return fmt.Errorf("cannot write webhook certificate: %v", err)
+	}
+	p.caBundle = cert.caCert
+	return p.inject(ctx)
+}
+
+// SetupWithManager adds a controller that injects the ca again when the
+// webhook configurations or the crds are updated, e.g. when the package
+// manager applies the crds of a new package revision.
+func (p *Provisioner) SetupWithManager(mgr ctrl.Manager) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGroupVersionKind)
+
+	owned := builder.WithPredicates(predicate.NewPredicateFuncs(p.owns))
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("webhook-provisioner").
+		For(crd, owned).
+		Watches(&source.Kind{Type: &admissionregistrationv1.MutatingWebhookConfiguration{}}, &handler.EnqueueRequestForObject{}, owned).
+		Watches(&source.Kind{Type: &admissionregistrationv1.ValidatingWebhookConfiguration{}}, &handler.EnqueueRequestForObject{}, owned).
+		Complete(reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+			return reconcile.Result{}, p.inject(ctx)
+		}))
+}
+
+// owns returns true for the crds and webhook configurations the provisioner
+// injects the ca into.
+func (p *Provisioner) owns(o client.Object) bool {
+	if _, ok := o.(*unstructured.Unstructured); ok {
+		for _, name := range conversionCRDs {
+			if o.GetName() == name {
+				return true
+			}
+		}
+		return false
+	}
+	return o.GetName() == p.o.ServiceName
+}
+
+// provisionService creates or updates the webhook service, which selects the
+// pods of the manager by the labels of this pod.
+func (p *Provisioner) provisionService(ctx context.Context) error {
+	pod := &corev1.Pod{}
+	if err := p.client.Get(ctx, types.NamespacedName{Namespace: p.o.Namespace, Name: p.o.PodName}, pod); err != nil {
+		return err
+	}
+	selector := make(map[string]string, len(pod.GetLabels()))
+	for k, v := range pod.GetLabels() {
+		if k != labelPodTemplateHash {
+			selector[k] = v
+		}
+	}
+	if len(selector) == 0 {
+		return fmt.Errorf("pod %s/%s has no labels to select it by", p.o.Namespace, p.o.PodName)
+	}
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: p.o.Namespace, Name: p.o.ServiceName}}
+	_, err := controllerutil.CreateOrUpdate(ctx, p.client, svc, func() error {
+		svc.Spec.Selector = selector
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:       "webhook",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       servicePort,
+			TargetPort: intstr.FromInt(p.o.Port),
+		}}
+		return nil
+	})
+	return err
+}
+
+// provisionCertificate returns the certificate of the secret of the service,
+// and generates a new one when the secret does not hold a valid certificate
+// for the service. The secret is shared by all replicas of the manager.
+func (p *Provisioner) provisionCertificate(ctx context.Context) (*certificate, error) {
+	dnsNames := serviceDNSNames(p.o.ServiceName, p.o.Namespace)
+	key := types.NamespacedName{Namespace: p.o.Namespace, Name: p.o.ServiceName + "-cert"}
+
+	var err error
+	for i := 0; i < provisionAttempts; i++ {
+		var cert *certificate
+		cert, err = p.getOrCreateCertificate(ctx, key, dnsNames)
+		if err == nil {
+			return cert, nil
+		}
+		if !apierrors.IsAlreadyExists(err) && !apierrors.IsConflict(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+func (p *Provisioner) getOrCreateCertificate(ctx context.Context, key types.NamespacedName, dnsNames []string) (*certificate, error) {
+	now := time.Now()
+	secret := &corev1.Secret{}
+	if err := p.client.Get(ctx, key, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		cert, err := newCertificate(dnsNames, now)
+		if err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Type:       corev1.SecretTypeTLS,
+			Data:       certificateData(cert),
+		}
+		p.log.Debug("create webhook certificate", "secret", key.String())
+		return cert, p.client.Create(ctx, secret)
+	}
+
+	cert := &certificate{
+		caCert: secret.Data[secretKeyCA],
+		cert:   secret.Data[corev1.TLSCertKey],
+		key:    secret.Data[corev1.TLSPrivateKeyKey],
+	}
+	err := cert.valid(dnsNames, now)
+	if err == nil {
+		return cert, nil
+	}
+	if cert, err = newCertificate(dnsNames, now); err != nil {
+		return nil, err
+	}
+	secret.Data = certificateData(cert)
+	p.log.Debug("renew webhook certificate", "secret", key.String(), "reason", err)
+	return cert, p.client.Update(ctx, secret)
+}
+
+// inject creates or updates the webhook configurations and the conversion
+// webhook of the crds with the webhook service and its ca.
+func (p *Provisioner) inject(ctx context.Context) error {
+	objs, err := p.webhookConfigurations()
+	if err != nil {
+		return fmt.Errorf("cannot decode webhook configurations: %v", err)
+	}
+	for _, obj := range objs {
+		desired := obj.DeepCopyObject().(client.Object)
+		if _, err := controllerutil.CreateOrUpdate(ctx, p.client, obj, func() error {
+			switch o := obj.(type) {
+			case *admissionregistrationv1.MutatingWebhookConfiguration:
+				o.Webhooks = desired.(*admissionregistrationv1.MutatingWebhookConfiguration).Webhooks
+			case *admissionregistrationv1.ValidatingWebhookConfiguration:
+				o.Webhooks = desired.(*admissionregistrationv1.ValidatingWebhookConfiguration).Webhooks
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("cannot provision webhook configuration %s: %v", obj.GetName(), err)
+		}
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"conversion": map[string]interface{}{
+				"strategy": "Webhook",
+				"webhook": map[string]interface{}{
+					"clientConfig": map[string]interface{}{
+						"service": map[string]interface{}{
+							"namespace": p.o.Namespace,
+							"name":      p.o.ServiceName,
+							"path":      conversionPath,
+							"port":      servicePort,
+						},
+						"caBundle": base64.StdEncoding.EncodeToString(p.caBundle),
+					},
+					"conversionReviewVersions": []string{"v1", "v1beta1"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range conversionCRDs {
+		crd := &unstructured.Unstructured{}
+		crd.SetGroupVersionKind(crdGroupVersionKind)
+		crd.SetName(name)
+		if err := p.client.Patch(ctx, crd, client.RawPatch(types.MergePatchType, patch)); err != nil {
+			return fmt.Errorf("cannot inject ca into crd %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// webhookConfigurations returns the generated webhook configurations, named
+// after and pointing to the webhook service, with the ca injected.
+func (p *Provisioner) webhookConfigurations() ([]client.Object, error) {
+	port := int32(servicePort)
+	clientConfig := func(cc *admissionregistrationv1.WebhookClientConfig) {
+		if cc.Service == nil {
+			cc.Service = &admissionregistrationv1.ServiceReference{}
+		}
+		cc.Service.Namespace = p.o.Namespace
+		cc.Service.Name = p.o.ServiceName
+		cc.Service.Port = &port
+		cc.CABundle = p.caBundle
+	}
+
+	objs := []client.Object{}
+	r := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(manifests)))
+	for {
+		doc, err := r.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		tm := &metav1.TypeMeta{}
+		if err := yaml.Unmarshal(doc, tm); err != nil {
+			return nil, err
+		}
+		switch tm.Kind {
+		case "MutatingWebhookConfiguration":
+			o := &admissionregistrationv1.MutatingWebhookConfiguration{}
+			if err := yaml.Unmarshal(doc, o); err != nil {
+				return nil, err
+			}
+			for i := range o.Webhooks {
+				clientConfig(&o.Webhooks[i].ClientConfig)
+			}
+			objs = append(objs, &admissionregistrationv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: p.o.ServiceName},
+				Webhooks:   o.Webhooks,
+			})
+		case "ValidatingWebhookConfiguration":
+			o := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+			if err := yaml.Unmarshal(doc, o); err != nil {
+				return nil, err
+			}
+			for i := range o.Webhooks {
+				clientConfig(&o.Webhooks[i].ClientConfig)
+			}
+			objs = append(objs, &admissionregistrationv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: p.o.ServiceName},
+				Webhooks:   o.Webhooks,
+			})
+		}
+	}
+}
+
+// writeCertificate writes the serving certificate and key to the files read
+// by the webhook server.
+func writeCertificate(dir string, cert *certificate) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for name, data := range map[string][]byte{
+		corev1.TLSCertKey:       cert.cert,
+		corev1.TLSPrivateKeyKey: cert.key,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func certificateData(cert *certificate) map[string][]byte {
+	return map[string][]byte{
+		secretKeyCA:             cert.caCert,
+		corev1.TLSCertKey:       cert.cert,
+		corev1.TLSPrivateKeyKey: cert.key,
+	}
+}
+
+// serviceDNSNames returns the dns names the api server may use to reach the
+// service.
+func serviceDNSNames(name, namespace string) []string {
+	return []string{
+		name + "." + namespace + ".svc",
+		name,
+		name + "." + namespace,
+		name + "." + namespace + ".svc.cluster.local",
+	}
+}
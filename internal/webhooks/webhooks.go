@@ -0,0 +1,67 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/yndd/nddr-organization/internal/shared"
+)
+
+// Setup package webhooks.
+func Setup(mgr ctrl.Manager, nddcopts *shared.NddControllerOptions) error {
+	for _, setup := range []func(ctrl.Manager, *shared.NddControllerOptions) error{
+		setupOrganization,
+		setupDeployment,
+	} {
+		if err := setup(mgr, nddcopts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validationResponse returns an allowed response with the warnings when errs
+// is empty and an invalid status response otherwise.
+func validationResponse(gk schema.GroupKind, name string, errs, warnings field.ErrorList) admission.Response {
+	if len(errs) == 0 {
+		w := make([]string, 0, len(warnings))
+		for _, warning := range warnings {
+			w = append(w, warning.Error())
+		}
+		return admission.Allowed("").WithWarnings(w...)
+	}
+	status := apierrors.NewInvalid(gk, name, errs).ErrStatus
+	return admission.Response{
+		AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed: false,
+			Result:  &status,
+		},
+	}
+}
+
+func decodeErrored(err error) admission.Response {
+	return admission.Errored(http.StatusBadRequest, err)
+}
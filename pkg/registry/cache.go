@@ -0,0 +1,137 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// RegisterNameIndex indexes organizations and deployments by the name of
+	// their register
+	RegisterNameIndex = "registry.registerName"
+
+	lookupSourceCache = "cache"
+	lookupSourceAPI   = "api"
+
+	lookupResultFound    = "found"
+	lookupResultNotFound = "not-found"
+	lookupResultError    = "error"
+)
+
+var (
+	lookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "nddr",
+		Subsystem: "registry",
+		Name:      "lookups_total",
+		Help:      "Number of registry lookups by method, source and result.",
+	}, []string{"method", "source", "result"})
+	lookupDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "nddr",
+		Subsystem: "registry",
+		Name:      "lookup_duration_seconds",
+		Help:      "Duration of registry lookups by method and source.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"method", "source"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(lookups, lookupDuration)
+}
+
+// IndexRegisters adds the RegisterNameIndex to the organizations and
+// deployments of the informer cache used by a registry created WithCache.
+// It must be called before the cache is started.
+func IndexRegisters(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &orgv1alpha2.Organization{}, RegisterNameIndex, func(o client.Object) []string {
+		return []string{o.GetName()}
+	}); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &orgv1alpha2.Deployment{}, RegisterNameIndex, func(o client.Object) []string {
+		dep, ok := o.(*orgv1alpha2.Deployment)
+		if !ok {
+			return nil
+		}
+		return []string{deploymentRegisterName(dep)}
+	})
+}
+
+// deploymentRegisterName returns the name of the register of the deployment
+func deploymentRegisterName(dep orgv1alpha2.Dp) string {
+	if orgName := dep.GetOrganizationName(); orgName != "" {
+		return registerName(orgName, dep.GetDeploymentName())
+	}
+	return dep.GetName()
+}
+
+// getCachedRegisterOwner returns the deployment or, when no deployment has the
+// register name, the organization that owns the register from the informer
+// cache using the RegisterNameIndex.
+func (r *registry) getCachedRegisterOwner(ctx context.Context, namespace, registerName string) (orgv1alpha2.Dp, orgv1alpha2.Org, error) {
+	deps := &orgv1alpha2.DeploymentList{}
+	if err := r.cache.List(ctx, deps,
+		client.InNamespace(namespace),
+		client.MatchingFields{RegisterNameIndex: registerName}); err != nil {
+		return nil, nil, err
+	}
+	switch len(deps.Items) {
+	case 0:
+	case 1:
+		return &deps.Items[0], nil, nil
+	default:
+		return nil, nil, fmt.Errorf("register %s is owned by %d deployments", registerName, len(deps.Items))
+	}
+
+	orgs := &orgv1alpha2.OrganizationList{}
+	if err := r.cache.List(ctx, orgs,
+		client.InNamespace(r.organizationNamespace(namespace)),
+		client.MatchingFields{RegisterNameIndex: registerName}); err != nil {
+		return nil, nil, err
+	}
+	if len(orgs.Items) == 0 {
+		return nil, nil, wrapNotFound(apierrors.NewNotFound(schema.GroupResource{Group: orgv1alpha2.Group, Resource: "organizations"}, registerName))
+	}
+	return nil, &orgs.Items[0], nil
+}
+
+// observeLookup records the result and duration of a registry lookup
+func (r *registry) observeLookup(method string, start time.Time, err error) {
+	source := lookupSourceAPI
+	if r.cache != nil {
+		source = lookupSourceCache
+	}
+	result := lookupResultFound
+	switch {
+	case errors.Is(err, ErrNotFound):
+		result = lookupResultNotFound
+	case err != nil:
+		result = lookupResultError
+	}
+	lookups.WithLabelValues(method, source, result).Inc()
+	lookupDuration.WithLabelValues(method, source).Observe(time.Since(start).Seconds())
+}
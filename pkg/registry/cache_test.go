@@ -0,0 +1,169 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/index/indextest"
+	"github.com/yndd/nddr-organization/pkg/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func organization(namespace, name string, register map[string]string) *orgv1alpha2.Organization {
+	org := &orgv1alpha2.Organization{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status: orgv1alpha2.OrganizationStatus{Organization: &orgv1alpha2.NddrOrganization{
+			State: &orgv1alpha2.NddrOrgDeploymentState{},
+		}},
+	}
+	org.SetStatus("up")
+	org.SetStateRegister(register)
+	return org
+}
+
+func deployment(namespace, orgName, name string, register map[string]string) *orgv1alpha2.Deployment {
+	dep := &orgv1alpha2.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: orgv1alpha2.DeploymentSpec{Deployment: &orgv1alpha2.OrgDeployment{
+			OrganizationRef: utils.StringPtr(orgName),
+		}},
+		Status: orgv1alpha2.DeploymentStatus{Deployment: &orgv1alpha2.NddrOrgDeployment{
+			State: &orgv1alpha2.NddrOrgDeploymentState{},
+		}},
+	}
+	dep.SetStatus("up")
+	dep.SetStateRegister(register)
+	return dep
+}
+
+func TestGetRegisterWithCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	orgRegister := map[string]string{"ipam": "nokia.default", "as": "nokia.default"}
+	depRegister := map[string]string{"ipam": "nokia.region1", "as": "nokia.default"}
+
+	cases := map[string]struct {
+		objs                  []client.Object
+		organizationNamespace func(string) string
+		namespace             string
+		registerName          string
+		want                  map[string]string
+		wantErr               error
+	}{
+		"Organization": {
+			objs:         []client.Object{organization("default", "nokia", orgRegister)},
+			namespace:    "default",
+			registerName: "nokia",
+			want:         orgRegister,
+		},
+		"Deployment": {
+			objs: []client.Object{
+				organization("default", "nokia", orgRegister),
+				deployment("default", "nokia", "nokia.region1", depRegister),
+			},
+			namespace:    "default",
+			registerName: "nokia.region1",
+			want:         depRegister,
+		},
+		"DeploymentByRegisterName": {
+			// the register name of the deployment is derived from its
+			// organization reference, not from its object name
+			objs: []client.Object{
+				organization("default", "nokia", orgRegister),
+				deployment("default", "nokia", "region1", depRegister),
+			},
+			namespace:    "default",
+			registerName: "nokia.region1",
+			want:         depRegister,
+		},
+		"DuplicateDeployments": {
+			objs: []client.Object{
+				organization("default", "nokia", orgRegister),
+				deployment("default", "nokia", "nokia.region1", depRegister),
+				deployment("default", "nokia", "region1", depRegister),
+			},
+			namespace:    "default",
+			registerName: "nokia.region1",
+			wantErr:      errors.New("register nokia.region1 is owned by 2 deployments"),
+		},
+		"OrganizationFallback": {
+			// a deployment in another namespace does not own the register
+			objs: []client.Object{
+				organization("default", "nokia", orgRegister),
+				deployment("tenant", "nokia", "nokia.region1", depRegister),
+			},
+			namespace:    "default",
+			registerName: "nokia",
+			want:         orgRegister,
+		},
+		"OrganizationNamespace": {
+			objs: []client.Object{
+				organization("orgs", "nokia", orgRegister),
+				deployment("tenant", "nokia", "nokia.region1", depRegister),
+			},
+			organizationNamespace: func(string) string { return "orgs" },
+			namespace:             "tenant",
+			registerName:          "nokia",
+			want:                  orgRegister,
+		},
+		"NotFound": {
+			objs:         []client.Object{organization("default", "nokia", orgRegister)},
+			namespace:    "tenant",
+			registerName: "nokia",
+			wantErr:      registry.ErrNotFound,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := indextest.NewCache(fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objs...).Build())
+			if err := registry.IndexRegisters(context.Background(), c); err != nil {
+				t.Fatal(err)
+			}
+			opts := []registry.Option{registry.WithCache(c)}
+			if tc.organizationNamespace != nil {
+				opts = append(opts, registry.WithOrganizationNamespace(tc.organizationNamespace))
+			}
+			r := registry.New(opts...)
+			defer r.Close()
+
+			got, err := r.GetRegister(context.Background(), tc.namespace, tc.registerName)
+			switch {
+			case tc.wantErr == nil && err != nil:
+				t.Fatalf("GetRegister(...): unexpected error %v", err)
+			case tc.wantErr != nil && err == nil:
+				t.Fatalf("GetRegister(...): want error %v", tc.wantErr)
+			case tc.wantErr != nil && !errors.Is(err, tc.wantErr) && err.Error() != tc.wantErr.Error():
+				t.Errorf("GetRegister(...): want error %v, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("GetRegister(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// secretKeyCA is the key of the ca certificate in a secret, as used by
+	// cert-manager
+	secretKeyCA = "ca.crt"
+	// secretTTL is how long a secret read with the api reader is reused
+	secretTTL = 30 * time.Second
+)
+
+// Credentials reference the secrets used to connect to the registry backends.
+// The secrets are read again when they are older than a short ttl, such that
+// rotated certificates and credentials are used without a restart.
+type Credentials struct {
+	// Namespace of the secrets
+	Namespace string
+	// CASecretName is the secret with the ca.crt that verifies the backends;
+	// when empty the ca.crt of the TLS secret is used if present
+	CASecretName string
+	// TLSSecretName is the kubernetes.io/tls secret with the client
+	// certificate and key
+	TLSSecretName string
+	// CredentialsSecretName is the kubernetes.io/basic-auth secret with the
+	// username and password sent with every request
+	CredentialsSecretName string
+	// ServerName overrides the name used to verify the backend certificates
+	ServerName string
+	// SkipVerify skips the verification of the backend certificates
+	SkipVerify bool
+	// Insecure connects without TLS
+	Insecure bool
+}
+
+// DefaultCredentials connect without TLS and without credentials.
+func DefaultCredentials() *Credentials {
+	return &Credentials{Insecure: true}
+}
+
+// OrganizationCredentials returns the credentials configured on the
+// organization, or nil when the organization has none.
+func OrganizationCredentials(org orgv1alpha2.Org) *Credentials {
+	c := org.GetRegistryCredentials()
+	if c == nil {
+		return nil
+	}
+	creds := &Credentials{Namespace: org.GetNamespace()}
+	if c.CASecretName != nil {
+		creds.CASecretName = *c.CASecretName
+	}
+	if c.TLSSecretName != nil {
+		creds.TLSSecretName = *c.TLSSecretName
+	}
+	if c.CredentialsSecretName != nil {
+		creds.CredentialsSecretName = *c.CredentialsSecretName
+	}
+	if c.ServerName != nil {
+		creds.ServerName = *c.ServerName
+	}
+	if c.SkipVerify != nil {
+		creds.SkipVerify = *c.SkipVerify
+	}
+	return creds
+}
+
+// id identifies the credentials in the client pool
+func (c *Credentials) id() string {
+	return strings.Join([]string{c.Namespace, c.CASecretName, c.TLSSecretName, c.CredentialsSecretName, c.ServerName,
+		fmt.Sprintf("%t", c.SkipVerify), fmt.Sprintf("%t", c.Insecure)}, "/")
+}
+
+// dialCredentials are credentials resolved from their secrets
+type dialCredentials struct {
+	// id identifies the credentials
+	id string
+	// version changes when one of the secrets changes
+	version string
+	opts    []grpc.DialOption
+}
+
+// getDialCredentials reads the secrets of the credentials and returns the
+// dial options that use them.
+func (r *registry) getDialCredentials(ctx context.Context, c *Credentials) (*dialCredentials, error) {
+	dc := &dialCredentials{id: c.id()}
+	versions := make([]string, 0, 3)
+
+	if c.CredentialsSecretName != "" {
+		secret, err := r.getSecret(ctx, c.Namespace, c.CredentialsSecretName)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, secret.GetResourceVersion())
+		dc.opts = append(dc.opts, grpc.WithPerRPCCredentials(&basicCredentials{
+			username:   string(secret.Data[corev1.BasicAuthUsernameKey]),
+			password:   string(secret.Data[corev1.BasicAuthPasswordKey]),
+			requireTLS: !c.Insecure,
+		}))
+	}
+
+	if c.Insecure {
+		dc.opts = append(dc.opts, grpc.WithInsecure())
+		dc.version = strings.Join(versions, "/")
+		return dc, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		Renegotiation:      tls.RenegotiateNever,
+		InsecureSkipVerify: c.SkipVerify,
+		ServerName:         c.ServerName,
+	}
+	var ca []byte
+	caSecretName := c.TLSSecretName
+	if c.TLSSecretName != "" {
+		secret, err := r.getSecret(ctx, c.Namespace, c.TLSSecretName)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, secret.GetResourceVersion())
+		cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate in secret %s/%s: %v", c.Namespace, c.TLSSecretName, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		ca = secret.Data[secretKeyCA]
+	}
+	if c.CASecretName != "" {
+		secret, err := r.getSecret(ctx, c.Namespace, c.CASecretName)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, secret.GetResourceVersion())
+		ca = secret.Data[secretKeyCA]
+		caSecretName = c.CASecretName
+		if len(ca) == 0 {
+			return nil, fmt.Errorf("secret %s/%s has no %s", c.Namespace, c.CASecretName, secretKeyCA)
+		}
+	}
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid ca certificate in secret %s/%s", c.Namespace, caSecretName)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	dc.opts = append(dc.opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	dc.version = strings.Join(versions, "/")
+	return dc, nil
+}
+
+// cachedSecret is a secret read with the api reader
+type cachedSecret struct {
+	secret *corev1.Secret
+	read   time.Time
+}
+
+// getSecret reads the secret with the api reader, reusing a secret read
+// within secretTTL, or with the client when the registry has no api reader.
+func (r *registry) getSecret(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if r.reader == nil {
+		secret := &corev1.Secret{}
+		if err := r.client.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("cannot get secret %s: %v", key, err)
+		}
+		return secret, nil
+	}
+
+	r.secretMutex.Lock()
+	defer r.secretMutex.Unlock()
+	if cs, ok := r.secrets[key]; ok && time.Since(cs.read) < secretTTL {
+		return cs.secret, nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.reader.Get(ctx, key, secret); err != nil {
+		delete(r.secrets, key)
+		return nil, fmt.Errorf("cannot get secret %s: %v", key, err)
+	}
+	r.secrets[key] = &cachedSecret{secret: secret, read: time.Now()}
+	return secret, nil
+}
+
+// basicCredentials send the username and password with every request
+type basicCredentials struct {
+	username   string
+	password   string
+	requireTLS bool
+}
+
+func (b *basicCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{
+		"username": b.username,
+		"password": b.password,
+	}, nil
+}
+
+func (b *basicCredentials) RequireTransportSecurity() bool {
+	return b.requireTLS
+}
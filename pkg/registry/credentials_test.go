@@ -0,0 +1,300 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// countingReader counts the reads of the wrapped reader
+type countingReader struct {
+	client.Reader
+	reads int
+}
+
+func (r *countingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	r.reads++
+	return r.Reader.Get(ctx, key, obj)
+}
+
+func testScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+// testCertificate returns a self-signed certificate and key in PEM
+func testCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "registry"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func secret(name string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ndd-system"},
+		Data:       data,
+	}
+}
+
+func TestGetSecretTTL(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme(t)).
+		WithObjects(secret("creds", map[string][]byte{corev1.BasicAuthUsernameKey: []byte("admin")})).Build()
+	reader := &countingReader{Reader: c}
+	r := New(WithClient(c), WithAPIReader(reader)).(*registry)
+	defer r.Close()
+	ctx := context.Background()
+
+	if _, err := r.getSecret(ctx, "ndd-system", "creds"); err != nil {
+		t.Fatal(err)
+	}
+	// a rotated secret is read again after the ttl
+	s := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "ndd-system", Name: "creds"}, s); err != nil {
+		t.Fatal(err)
+	}
+	s.Data[corev1.BasicAuthUsernameKey] = []byte("rotated")
+	if err := c.Update(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.getSecret(ctx, "ndd-system", "creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.reads != 1 || string(got.Data[corev1.BasicAuthUsernameKey]) != "admin" {
+		t.Errorf("getSecret(...) within the ttl: want the cached secret and 1 read, got %s and %d reads",
+			got.Data[corev1.BasicAuthUsernameKey], reader.reads)
+	}
+
+	r.secrets[types.NamespacedName{Namespace: "ndd-system", Name: "creds"}].read = time.Now().Add(-secretTTL)
+	got, err = r.getSecret(ctx, "ndd-system", "creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.reads != 2 || string(got.Data[corev1.BasicAuthUsernameKey]) != "rotated" {
+		t.Errorf("getSecret(...) after the ttl: want the rotated secret and 2 reads, got %s and %d reads",
+			got.Data[corev1.BasicAuthUsernameKey], reader.reads)
+	}
+
+	// a deleted secret is not served from the cache after the ttl
+	if err := c.Delete(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	r.secrets[types.NamespacedName{Namespace: "ndd-system", Name: "creds"}].read = time.Now().Add(-secretTTL)
+	if _, err := r.getSecret(ctx, "ndd-system", "creds"); err == nil {
+		t.Errorf("getSecret(...) of a deleted secret: want error")
+	}
+	if _, ok := r.secrets[types.NamespacedName{Namespace: "ndd-system", Name: "creds"}]; ok {
+		t.Errorf("getSecret(...) of a deleted secret: want the cached secret removed")
+	}
+}
+
+func TestGetDialCredentials(t *testing.T) {
+	cert, key := testCertificate(t)
+	objs := []client.Object{
+		secret("creds", map[string][]byte{corev1.BasicAuthUsernameKey: []byte("admin"), corev1.BasicAuthPasswordKey: []byte("secret")}),
+		secret("tls", map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key, secretKeyCA: cert}),
+		secret("ca", map[string][]byte{secretKeyCA: cert}),
+		secret("no-ca", map[string][]byte{}),
+		secret("invalid-tls", map[string][]byte{corev1.TLSCertKey: []byte("invalid")}),
+	}
+
+	cases := map[string]struct {
+		creds    *Credentials
+		wantOpts int
+		wantErr  bool
+	}{
+		"Default": {
+			creds:    DefaultCredentials(),
+			wantOpts: 1,
+		},
+		"BasicAuthInsecure": {
+			creds:    &Credentials{Namespace: "ndd-system", CredentialsSecretName: "creds", Insecure: true},
+			wantOpts: 2,
+		},
+		"TLS": {
+			creds:    &Credentials{Namespace: "ndd-system", TLSSecretName: "tls"},
+			wantOpts: 1,
+		},
+		"TLSWithCAAndBasicAuth": {
+			creds:    &Credentials{Namespace: "ndd-system", CASecretName: "ca", CredentialsSecretName: "creds"},
+			wantOpts: 2,
+		},
+		"SystemRoots": {
+			creds:    &Credentials{Namespace: "ndd-system"},
+			wantOpts: 1,
+		},
+		"MissingCredentialsSecret": {
+			creds:   &Credentials{Namespace: "ndd-system", CredentialsSecretName: "missing"},
+			wantErr: true,
+		},
+		"MissingTLSSecret": {
+			creds:   &Credentials{Namespace: "ndd-system", TLSSecretName: "missing"},
+			wantErr: true,
+		},
+		"MissingCASecret": {
+			creds:   &Credentials{Namespace: "ndd-system", CASecretName: "missing"},
+			wantErr: true,
+		},
+		"CASecretWithoutCA": {
+			creds:   &Credentials{Namespace: "ndd-system", CASecretName: "no-ca"},
+			wantErr: true,
+		},
+		"InvalidTLSSecret": {
+			creds:   &Credentials{Namespace: "ndd-system", TLSSecretName: "invalid-tls"},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testScheme(t)).WithObjects(objs...).Build()
+			r := New(WithClient(c), WithAPIReader(c)).(*registry)
+			defer r.Close()
+
+			dc, err := r.getDialCredentials(context.Background(), tc.creds)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("getDialCredentials(...): unexpected error %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if len(dc.opts) != tc.wantOpts {
+				t.Errorf("getDialCredentials(...): want %d dial options, got %d", tc.wantOpts, len(dc.opts))
+			}
+			if dc.id != tc.creds.id() {
+				t.Errorf("getDialCredentials(...): want id %s, got %s", tc.creds.id(), dc.id)
+			}
+		})
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	// the insecure default is only used without configured credentials
+	if got := New().(*registry).credentials; !got.Insecure {
+		t.Errorf("New(): want insecure default credentials, got %+v", got)
+	}
+	creds := &Credentials{Namespace: "ndd-system", TLSSecretName: "tls"}
+	if got := New(WithCredentials(creds)).(*registry).credentials; got != creds {
+		t.Errorf("New(WithCredentials(...)): want the configured credentials, got %+v", got)
+	}
+
+	org := &orgv1alpha2.Organization{
+		ObjectMeta: metav1.ObjectMeta{Name: "nokia", Namespace: "default"},
+		Spec:       orgv1alpha2.OrganizationSpec{Organization: &orgv1alpha2.OrgOrganization{}},
+	}
+	if got := OrganizationCredentials(org); got != nil {
+		t.Errorf("OrganizationCredentials(...) without credentials: want nil, got %+v", got)
+	}
+	org.Spec.Organization.RegistryCredentials = &orgv1alpha2.OrgRegistryCredentials{
+		CASecretName: utils.StringPtr("ca"),
+		SkipVerify:   utils.BoolPtr(true),
+	}
+	want := &Credentials{Namespace: "default", CASecretName: "ca", SkipVerify: true}
+	if got := OrganizationCredentials(org); *got != *want {
+		t.Errorf("OrganizationCredentials(...): want %+v, got %+v", want, got)
+	}
+}
+
+// TestMissingSecretDoesNotFallBackToInsecure verifies that the registry
+// fails to connect when a secret of its TLS credentials is missing, rather
+// than connecting with the insecure default credentials.
+func TestMissingSecretDoesNotFallBackToInsecure(t *testing.T) {
+	scheme := testScheme(t)
+	backend := []client.Object{
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "ipam", Namespace: "ndd-system", Labels: DefaultBackends("ndd-system")[RegisterKindIpam.String()].Selector}},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ipam-x1",
+				Namespace: "ndd-system",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "ipam"},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Ports:       []discoveryv1.EndpointPort{{Name: utils.StringPtr(defaultBackendPortName), Port: int32Ptr(9999)}},
+			Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"127.0.0.1"}}},
+		},
+	}
+	org := &orgv1alpha2.Organization{
+		ObjectMeta: metav1.ObjectMeta{Name: "nokia", Namespace: "default"},
+		Spec: orgv1alpha2.OrganizationSpec{Organization: &orgv1alpha2.OrgOrganization{
+			RegistryCredentials: &orgv1alpha2.OrgRegistryCredentials{TLSSecretName: utils.StringPtr("missing")},
+		}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(backend, org)...).Build()
+
+	// the credentials of the --registry-insecure=false flags
+	creds := &Credentials{Namespace: "ndd-system", TLSSecretName: "missing"}
+	r := New(WithClient(c), WithAPIReader(c), WithCredentials(creds), WithBackends(DefaultBackends("ndd-system")))
+	defer r.Close()
+
+	if _, err := r.GetRegistryClient(context.Background(), RegisterKindIpam.String()); err == nil || errors.Is(err, ErrBackendUnavailable) {
+		t.Errorf("GetRegistryClient(...): want a missing secret error, got %v", err)
+	}
+	// the organization credentials do not fall back to the registry
+	// credentials either
+	if _, err := r.GetOrganizationRegistryClient(context.Background(), "default", "nokia", RegisterKindIpam.String()); err == nil || errors.Is(err, ErrBackendUnavailable) {
+		t.Errorf("GetOrganizationRegistryClient(...): want a missing secret error, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	pkgmetav1 "github.com/yndd/ndd-core/apis/pkg/meta/v1"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultBackendPortName is the name of the gnmi service port of ndd
+	// intent packages
+	defaultBackendPortName = "gnmi"
+)
+
+// Backend defines how the registry backend serving a register kind is
+// discovered: through the services in Namespace that match the Selector
+// labels, and the ready endpoints of their port with PortName.
+type Backend struct {
+	Namespace string
+	Selector  map[string]string
+	PortName  string
+}
+
+// DefaultBackendPackages returns the names of the ndd registry packages
+// serving each register kind.
+func DefaultBackendPackages() map[string]string {
+	return map[string]string{
+		RegisterKindIpam.String():            "nddr-ipam",
+		RegisterKindAs.String():              "nddr-aspool",
+		RegisterKindNetworkInstance.String(): "nddr-ni-registry",
+	}
+}
+
+// DefaultBackends returns the backends of the ndd registry packages, which
+// are labeled with the package name by ndd-core, in the supplied namespace.
+func DefaultBackends(namespace string) map[string]*Backend {
+	return NewBackends(namespace, DefaultBackendPackages())
+}
+
+// NewBackends returns the backends of the supplied packages per register
+// kind, whose services are labeled with the package name by ndd-core, in the
+// supplied namespace.
+func NewBackends(namespace string, packages map[string]string) map[string]*Backend {
+	b := make(map[string]*Backend, len(packages))
+	for kind, pkgName := range packages {
+		b[kind] = &Backend{
+			Namespace: namespace,
+			Selector:  map[string]string{pkgmetav1.LabelPkgMeta: pkgName},
+			PortName:  defaultBackendPortName,
+		}
+	}
+	return b
+}
+
+// GetRegistryEndpoints returns the addresses of the ready endpoints of the
+// backend serving the register kind. The services and endpoint slices are
+// listed with the backend reader when set, which needs list and watch access
+// to services and discovery.k8s.io endpointslices in the backend namespace.
+func (r *registry) GetRegistryEndpoints(ctx context.Context, registerKind string) (endpoints []string, err error) {
+	ctx, span := startSpan(ctx, "GetRegistryEndpoints", attribute.String(attrRegisterKind, registerKind))
+	defer func() { endSpan(span, err) }()
+
+	backend, ok := r.backends[registerKind]
+	if !ok {
+		return nil, wrapError(ErrInvalidRegisterName, fmt.Errorf("no backend for register kind %s", registerKind))
+	}
+
+	reader := r.backendReader
+	if reader == nil {
+		reader = r.client
+	}
+
+	svcs := &corev1.ServiceList{}
+	if err := reader.List(ctx, svcs,
+		client.InNamespace(backend.Namespace),
+		client.MatchingLabels(backend.Selector)); err != nil {
+		return nil, wrapError(ErrBackendUnavailable, err)
+	}
+
+	endpoints = make([]string, 0)
+	for _, svc := range svcs.Items {
+		epSlices := &discoveryv1.EndpointSliceList{}
+		if err := reader.List(ctx, epSlices,
+			client.InNamespace(backend.Namespace),
+			client.MatchingLabels{discoveryv1.LabelServiceName: svc.GetName()}); err != nil {
+			return nil, wrapError(ErrBackendUnavailable, err)
+		}
+		for _, epSlice := range epSlices.Items {
+			endpoints = append(endpoints, getReadyEndpoints(epSlice, backend.PortName)...)
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, wrapError(ErrBackendUnavailable, fmt.Errorf("no ready endpoint for register %s in namespace %s", registerKind, backend.Namespace))
+	}
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+// getReadyEndpoints returns the addresses of the ready endpoints of the
+// endpoint slice with the port of the supplied name; terminating endpoints
+// are skipped.
+func getReadyEndpoints(epSlice discoveryv1.EndpointSlice, portName string) []string {
+	var port *int32
+	for _, p := range epSlice.Ports {
+		if p.Port != nil && (portName == "" || (p.Name != nil && *p.Name == portName)) {
+			port = p.Port
+			break
+		}
+	}
+	if port == nil {
+		return nil
+	}
+
+	endpoints := make([]string, 0)
+	for _, ep := range epSlice.Endpoints {
+		// an unknown ready state is interpreted as ready
+		if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+			continue
+		}
+		if ep.Conditions.Terminating != nil && *ep.Conditions.Terminating {
+			continue
+		}
+		for _, address := range ep.Addresses {
+			endpoints = append(endpoints, net.JoinHostPort(address, strconv.Itoa(int(*port))))
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	pkgmetav1 "github.com/yndd/ndd-core/apis/pkg/meta/v1"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func TestGetReadyEndpoints(t *testing.T) {
+	gnmi := discoveryv1.EndpointPort{Name: utils.StringPtr("gnmi"), Port: int32Ptr(9999)}
+	metrics := discoveryv1.EndpointPort{Name: utils.StringPtr("metrics"), Port: int32Ptr(8443)}
+	endpoint := func(address string, c discoveryv1.EndpointConditions) discoveryv1.Endpoint {
+		return discoveryv1.Endpoint{Addresses: []string{address}, Conditions: c}
+	}
+
+	cases := map[string]struct {
+		ports     []discoveryv1.EndpointPort
+		endpoints []discoveryv1.Endpoint
+		portName  string
+		want      []string
+	}{
+		"Ready": {
+			ports: []discoveryv1.EndpointPort{metrics, gnmi},
+			endpoints: []discoveryv1.Endpoint{
+				endpoint("10.0.0.1", discoveryv1.EndpointConditions{Ready: utils.BoolPtr(true)}),
+			},
+			portName: "gnmi",
+			want:     []string{"10.0.0.1:9999"},
+		},
+		"UnknownReadyIsReady": {
+			ports: []discoveryv1.EndpointPort{gnmi},
+			endpoints: []discoveryv1.Endpoint{
+				endpoint("10.0.0.1", discoveryv1.EndpointConditions{}),
+			},
+			portName: "gnmi",
+			want:     []string{"10.0.0.1:9999"},
+		},
+		"NotReady": {
+			ports: []discoveryv1.EndpointPort{gnmi},
+			endpoints: []discoveryv1.Endpoint{
+				endpoint("10.0.0.1", discoveryv1.EndpointConditions{Ready: utils.BoolPtr(false)}),
+				endpoint("10.0.0.2", discoveryv1.EndpointConditions{Ready: utils.BoolPtr(true)}),
+			},
+			portName: "gnmi",
+			want:     []string{"10.0.0.2:9999"},
+		},
+		"Terminating": {
+			ports: []discoveryv1.EndpointPort{gnmi},
+			endpoints: []discoveryv1.Endpoint{
+				endpoint("10.0.0.1", discoveryv1.EndpointConditions{Terminating: utils.BoolPtr(true)}),
+				endpoint("10.0.0.2", discoveryv1.EndpointConditions{Terminating: utils.BoolPtr(false)}),
+			},
+			portName: "gnmi",
+			want:     []string{"10.0.0.2:9999"},
+		},
+		"MissingPortName": {
+			ports: []discoveryv1.EndpointPort{metrics},
+			endpoints: []discoveryv1.Endpoint{
+				endpoint("10.0.0.1", discoveryv1.EndpointConditions{Ready: utils.BoolPtr(true)}),
+			},
+			portName: "gnmi",
+		},
+		"UnnamedPort": {
+			ports: []discoveryv1.EndpointPort{{Port: int32Ptr(9999)}},
+			endpoints: []discoveryv1.Endpoint{
+				endpoint("10.0.0.1", discoveryv1.EndpointConditions{Ready: utils.BoolPtr(true)}),
+			},
+			portName: "gnmi",
+		},
+		"AnyPort": {
+			ports: []discoveryv1.EndpointPort{metrics, gnmi},
+			endpoints: []discoveryv1.Endpoint{
+				endpoint("10.0.0.1", discoveryv1.EndpointConditions{Ready: utils.BoolPtr(true)}),
+			},
+			want: []string{"10.0.0.1:8443"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := getReadyEndpoints(discoveryv1.EndpointSlice{Ports: tc.ports, Endpoints: tc.endpoints}, tc.portName)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("getReadyEndpoints(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetRegistryEndpointsBackendReader(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      "ipam",
+		Namespace: "backends",
+		Labels:    map[string]string{pkgmetav1.LabelPkgMeta: "nddr-ipam"},
+	}}
+	epSlice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ipam-x1",
+			Namespace: "backends",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "ipam"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports:       []discoveryv1.EndpointPort{{Name: utils.StringPtr("gnmi"), Port: int32Ptr(9999)}},
+		Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}}},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(svc, epSlice).Build()
+
+	// the client has no backends, only the backend reader does
+	r := New(
+		WithClient(fake.NewClientBuilder().WithScheme(scheme).Build()),
+		WithBackends(NewBackends("backends", map[string]string{RegisterKindIpam.String(): "nddr-ipam"})),
+		WithBackendReader(reader),
+	)
+	defer r.Close()
+
+	got, err := r.GetRegistryEndpoints(context.Background(), RegisterKindIpam.String())
+	if err != nil {
+		t.Fatalf("GetRegistryEndpoints(...): %v", err)
+	}
+	if diff := cmp.Diff([]string{"10.0.0.1:9999"}, got); diff != "" {
+		t.Errorf("GetRegistryEndpoints(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	// ErrInvalidRegisterName is returned for a register name or kind the
+	// registry cannot resolve; it is a user misconfiguration.
+	ErrInvalidRegisterName = errors.New("invalid register name")
+	// ErrNotFound is returned when no organization or deployment owns the
+	// register.
+	ErrNotFound = errors.New("register not found")
+	// ErrBackendUnavailable is returned when no backend of a register kind is
+	// ready or healthy; the request can be retried later.
+	ErrBackendUnavailable = errors.New("registry backend unavailable")
+)
+
+// ErrCriticalRegisterMissing is returned when a required register is not
+// present in the register of an organization or deployment. It matches any
+// ErrCriticalRegisterMissing with errors.Is when the target has no Kind.
+type ErrCriticalRegisterMissing struct {
+	Kind string
+}
+
+func (e *ErrCriticalRegisterMissing) Error() string {
+	return fmt.Sprintf("critical register %s not found in registry", e.Kind)
+}
+
+func (e *ErrCriticalRegisterMissing) Is(target error) bool {
+	t, ok := target.(*ErrCriticalRegisterMissing)
+	return ok && (t.Kind == "" || t.Kind == e.Kind)
+}
+
+// registryError annotates an error with one of the registry sentinel errors;
+// the underlying error, e.g. a kubernetes api error, remains available
+// through errors.As.
+type registryError struct {
+	sentinel error
+	err      error
+}
+
+func (e *registryError) Error() string {
+	return fmt.Sprintf("%s: %s", e.sentinel, e.err)
+}
+
+func (e *registryError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *registryError) Unwrap() error {
+	return e.err
+}
+
+func wrapError(sentinel, err error) error {
+	return &registryError{sentinel: sentinel, err: err}
+}
+
+// wrapNotFound returns ErrNotFound for a kubernetes not found error
+func wrapNotFound(err error) error {
+	if apierrors.IsNotFound(err) {
+		return wrapError(ErrNotFound, err)
+	}
+	return err
+}
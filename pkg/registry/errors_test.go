@@ -0,0 +1,111 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestErrCriticalRegisterMissingIs(t *testing.T) {
+	err := fmt.Errorf("get register: %w", &ErrCriticalRegisterMissing{Kind: "ipam"})
+
+	cases := map[string]struct {
+		target error
+		want   bool
+	}{
+		"AnyKind": {
+			target: &ErrCriticalRegisterMissing{},
+			want:   true,
+		},
+		"SameKind": {
+			target: &ErrCriticalRegisterMissing{Kind: "ipam"},
+			want:   true,
+		},
+		"OtherKind": {
+			target: &ErrCriticalRegisterMissing{Kind: "as"},
+		},
+		"OtherError": {
+			target: ErrNotFound,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := errors.Is(err, tc.target); got != tc.want {
+				t.Errorf("errors.Is(%v, %v): want %t, got %t", err, tc.target, tc.want, got)
+			}
+		})
+	}
+
+	var missing *ErrCriticalRegisterMissing
+	if !errors.As(err, &missing) || missing.Kind != "ipam" {
+		t.Errorf("errors.As(%v, ...): want kind ipam, got %v", err, missing)
+	}
+}
+
+func TestRegistryError(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "organizations"}, "nokia")
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "organizations"}, "nokia", errors.New("rbac"))
+
+	cases := map[string]struct {
+		err          error
+		wantSentinel error
+		wantAPIError bool
+	}{
+		"NotFound": {
+			err:          wrapNotFound(notFound),
+			wantSentinel: ErrNotFound,
+			wantAPIError: true,
+		},
+		"OtherAPIError": {
+			// only not found errors are annotated with ErrNotFound
+			err:          wrapNotFound(forbidden),
+			wantAPIError: true,
+		},
+		"Wrapped": {
+			err:          fmt.Errorf("get register: %w", wrapError(ErrBackendUnavailable, errors.New("no endpoints"))),
+			wantSentinel: ErrBackendUnavailable,
+		},
+	}
+	sentinels := []error{ErrInvalidRegisterName, ErrNotFound, ErrBackendUnavailable}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, sentinel := range sentinels {
+				if got, want := errors.Is(tc.err, sentinel), sentinel == tc.wantSentinel; got != want {
+					t.Errorf("errors.Is(%v, %v): want %t, got %t", tc.err, sentinel, want, got)
+				}
+			}
+			// the underlying api error remains available
+			var status apierrors.APIStatus
+			if got := errors.As(tc.err, &status); got != tc.wantAPIError {
+				t.Errorf("errors.As(%v, APIStatus): want %t, got %t", tc.err, tc.wantAPIError, got)
+			}
+		})
+	}
+
+	err := wrapError(ErrNotFound, notFound)
+	if want := "register not found: " + notFound.Error(); err.Error() != want {
+		t.Errorf("Error(): want %q, got %q", want, err.Error())
+	}
+	if !apierrors.IsNotFound(errors.Unwrap(err)) {
+		t.Errorf("errors.Unwrap(%v): want the api not found error", err)
+	}
+}
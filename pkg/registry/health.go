@@ -0,0 +1,198 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProbeInterval is the default interval of the backend probes.
+const DefaultProbeInterval = 10 * time.Second
+
+// BackendStatus is the reachability of the backend serving a register kind.
+type BackendStatus struct {
+	Kind string `json:"kind"`
+	// Healthy is true when an endpoint of the backend responded to a health
+	// check
+	Healthy   bool             `json:"healthy"`
+	Error     string           `json:"error,omitempty"`
+	Endpoints []EndpointStatus `json:"endpoints,omitempty"`
+}
+
+// EndpointStatus is the state of the pooled connection to an endpoint of a
+// backend.
+type EndpointStatus struct {
+	Address   string     `json:"address"`
+	Connected bool       `json:"connected"`
+	Failures  int        `json:"failures,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	LastCheck *time.Time `json:"lastCheck,omitempty"`
+}
+
+// GetBackendStatus discovers the endpoints of every configured backend and
+// health checks them, returning the status per register kind.
+func (r *registry) GetBackendStatus(ctx context.Context) []BackendStatus {
+	ctx, span := startSpan(ctx, "GetBackendStatus")
+	defer span.End()
+
+	kinds := make([]string, 0, len(r.backends))
+	for kind := range r.backends {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	statuses := make([]BackendStatus, 0, len(kinds))
+	for _, kind := range kinds {
+		s := BackendStatus{Kind: kind}
+		if _, err := r.getRegistryClient(ctx, kind, r.credentials); err != nil {
+			s.Error = err.Error()
+		} else {
+			s.Healthy = true
+		}
+		s.Endpoints = r.pool.status(kind)
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// status returns the state of the pooled connections of the register kind.
+func (p *clientPool) status(kind string) []EndpointStatus {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	endpoints := make([]EndpointStatus, 0)
+	for key, pc := range p.conns {
+		if key.kind != kind {
+			continue
+		}
+		e := EndpointStatus{
+			Address:   key.address,
+			Connected: pc.conn != nil,
+			Failures:  pc.failures,
+		}
+		if !pc.lastCheck.IsZero() {
+			lastCheck := pc.lastCheck
+			e.LastCheck = &lastCheck
+		}
+		if pc.lastErr != nil {
+			e.LastError = pc.lastErr.Error()
+		}
+		endpoints = append(endpoints, e)
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpoints[i].Address < endpoints[j].Address
+	})
+	return endpoints
+}
+
+// A BackendProber checks the registry backends in the background and caches
+// the result, such that a readiness check does not dial the backends.
+type BackendProber struct {
+	registry Registry
+	interval time.Duration
+
+	m        sync.RWMutex
+	statuses []BackendStatus
+	checked  time.Time
+}
+
+// NewBackendProber returns a prober that checks the backends of the registry
+// every interval.
+func NewBackendProber(r Registry, interval time.Duration) *BackendProber {
+	return &BackendProber{
+		registry: r,
+		interval: interval,
+	}
+}
+
+// Start probes the backends until the context is done; it implements the
+// manager Runnable.
+func (p *BackendProber) Start(ctx context.Context) error {
+	t := time.NewTicker(p.interval)
+	defer t.Stop()
+	for {
+		p.probe(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns false, every replica reports its readiness.
+func (p *BackendProber) NeedLeaderElection() bool {
+	return false
+}
+
+func (p *BackendProber) probe(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+	statuses := p.registry.GetBackendStatus(ctx)
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	p.statuses = statuses
+	p.checked = time.Now()
+}
+
+// ReadyzCheck returns a readiness checker that fails when the last probe
+// found a register kind whose backend has no endpoint that responds to a
+// health check, or when the backends were not probed recently.
+func (p *BackendProber) ReadyzCheck() func(req *http.Request) error {
+	return func(req *http.Request) error {
+		p.m.RLock()
+		defer p.m.RUnlock()
+
+		if p.checked.IsZero() {
+			return fmt.Errorf("registry backends not probed yet")
+		}
+		if age := time.Since(p.checked); age > 3*p.interval {
+			return fmt.Errorf("registry backends last probed %s ago", age.Round(time.Second))
+		}
+		unhealthy := make([]string, 0)
+		for _, s := range p.statuses {
+			if !s.Healthy {
+				unhealthy = append(unhealthy, fmt.Sprintf("%s: %s", s.Kind, s.Error))
+			}
+		}
+		if len(unhealthy) > 0 {
+			return fmt.Errorf("registry backends unavailable: %s", strings.Join(unhealthy, "; "))
+		}
+		return nil
+	}
+}
+
+// BackendStatusHandler returns a handler that reports the status of the
+// registry backends as json.
+func BackendStatusHandler(r Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r.GetBackendStatus(req.Context())); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
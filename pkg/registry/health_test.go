@@ -0,0 +1,68 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yndd/nddr-organization/pkg/registry"
+	"github.com/yndd/nddr-organization/pkg/registry/registrytest"
+)
+
+func TestBackendProberReadyzCheck(t *testing.T) {
+	cases := map[string]struct {
+		registry *registrytest.Registry
+		probe    bool
+		wantErr  bool
+	}{
+		"NotProbed": {
+			registry: registrytest.New(),
+			wantErr:  true,
+		},
+		"Healthy": {
+			registry: registrytest.New(),
+			probe:    true,
+		},
+		"Unhealthy": {
+			registry: registrytest.New().WithError(registrytest.MethodGetRegistryClient, errors.New("connection refused")),
+			probe:    true,
+			wantErr:  true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := registry.NewBackendProber(tc.registry, time.Minute)
+			if tc.probe {
+				// a done context probes once
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				if err := p.Start(ctx); err != nil {
+					t.Fatalf("Start(...): %v", err)
+				}
+			}
+			err := p.ReadyzCheck()(&http.Request{})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ReadyzCheck(...): got error %v, want error %t", err, tc.wantErr)
+			}
+		})
+	}
+}
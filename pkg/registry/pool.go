@@ -0,0 +1,354 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yndd/nddo-grpc/resource/resourcepb"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+	defaultDialTimeout         = 10 * time.Second
+	defaultBaseBackoff         = 1 * time.Second
+	defaultMaxBackoff          = 1 * time.Minute
+	maxMsgSize                 = 512 * 1024 * 1024
+)
+
+// poolKey identifies a pooled connection by register kind, backend address
+// and the credentials used to connect
+type poolKey struct {
+	kind        string
+	address     string
+	credentials string
+}
+
+// poolConn is a pooled grpc connection to a registry backend. Its fields are
+// guarded by the pool mutex; dialing serializes the dials and health checks of
+// the connection, which run without the pool mutex.
+type poolConn struct {
+	dialing sync.Mutex
+
+	conn   *grpc.ClientConn
+	client resourcepb.ResourceClient
+	// version of the credentials the connection was dialed with
+	version string
+	// lastCheck is the time of the last successful health check
+	lastCheck time.Time
+	// failures is the number of consecutive failed dials or health checks
+	failures int
+	// retryAfter is the time before which the backend is not redialed
+	retryAfter time.Time
+	lastErr    error
+	// removed is set when the connection is removed from the pool
+	removed bool
+}
+
+// clientPool keeps health checked grpc connections to the registry backends,
+// keyed by register kind and backend address. The connections of a register
+// kind are invalidated when the endpoints of its backend change. The pool
+// mutex is never held while dialing or health checking a backend.
+type clientPool struct {
+	m     sync.Mutex
+	conns map[poolKey]*poolConn
+	// endpoints are the last known endpoints per register kind
+	endpoints map[string][]string
+
+	healthCheckInterval time.Duration
+	baseBackoff         time.Duration
+	maxBackoff          time.Duration
+}
+
+func newClientPool() *clientPool {
+	return &clientPool{
+		conns:               make(map[poolKey]*poolConn),
+		endpoints:           make(map[string][]string),
+		healthCheckInterval: defaultHealthCheckInterval,
+		baseBackoff:         defaultBaseBackoff,
+		maxBackoff:          defaultMaxBackoff,
+	}
+}
+
+// get returns a healthy client to one of the endpoints of the register kind,
+// in the order of the endpoints.
+func (p *clientPool) get(ctx context.Context, kind string, endpoints []string, dc *dialCredentials) (resourcepb.ResourceClient, error) {
+	p.m.Lock()
+	p.sync(kind, endpoints)
+	p.m.Unlock()
+
+	var lastErr error
+	for _, address := range endpoints {
+		client, err := p.connect(ctx, poolKey{kind: kind, address: address, credentials: dc.id}, dc)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return client, nil
+	}
+	return nil, wrapError(ErrBackendUnavailable, fmt.Errorf("no healthy endpoint for register %s: %v", kind, lastErr))
+}
+
+// sync invalidates the connections of the register kind when its endpoints
+// changed since the last call. The caller holds the pool mutex.
+func (p *clientPool) sync(kind string, endpoints []string) {
+	if equalEndpoints(p.endpoints[kind], endpoints) {
+		return
+	}
+	current := make(map[string]struct{}, len(endpoints))
+	for _, address := range endpoints {
+		current[address] = struct{}{}
+	}
+	for key, pc := range p.conns {
+		if key.kind != kind {
+			continue
+		}
+		if _, ok := current[key.address]; !ok {
+			p.remove(key, pc)
+		}
+	}
+	p.endpoints[kind] = append([]string(nil), endpoints...)
+}
+
+// connect returns a client of the pooled connection of the key, dialing it
+// when it does not exist and health checking it when the last check is older
+// than the health check interval. A backend that failed is not redialed
+// before its backoff expires. A connection is redialed when its credentials
+// rotated. Concurrent callers of the same key wait for a single dial and
+// health check.
+func (p *clientPool) connect(ctx context.Context, key poolKey, dc *dialCredentials) (resourcepb.ResourceClient, error) {
+	pc := p.entry(key)
+
+	pc.dialing.Lock()
+	defer pc.dialing.Unlock()
+
+	conn, client, healthy, err := p.state(pc, key, dc)
+	if err != nil {
+		return nil, err
+	}
+	if healthy {
+		return client, nil
+	}
+	if conn == nil {
+		if conn, client, err = p.dial(ctx, pc, key, dc); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.checked(pc, key, conn, healthCheck(ctx, conn)); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// entry returns the pooled connection of the key, adding it when missing.
+func (p *clientPool) entry(key poolKey) *poolConn {
+	p.m.Lock()
+	defer p.m.Unlock()
+	pc, ok := p.conns[key]
+	if !ok {
+		pc = &poolConn{}
+		p.conns[key] = pc
+	}
+	return pc
+}
+
+// state returns the connection of pc, and whether it was health checked
+// within the health check interval. The connection is nil when it must be
+// dialed; it is closed first when its credentials rotated.
+func (p *clientPool) state(pc *poolConn, key poolKey, dc *dialCredentials) (*grpc.ClientConn, resourcepb.ResourceClient, bool, error) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if pc.removed {
+		return nil, nil, false, errRemoved(key)
+	}
+	if pc.conn != nil && pc.version != dc.version {
+		pc.conn.Close()
+		pc.conn = nil
+		pc.client = nil
+		pc.lastCheck = time.Time{}
+	}
+	if pc.conn == nil && time.Now().Before(pc.retryAfter) {
+		return nil, nil, false, fmt.Errorf("backend %s backing off after %d failures: %v", key.address, pc.failures, pc.lastErr)
+	}
+	return pc.conn, pc.client, pc.conn != nil && time.Since(pc.lastCheck) < p.healthCheckInterval, nil
+}
+
+// dial dials the backend of the key without the pool mutex, and stores the
+// connection in pc unless pc was removed from the pool in the meantime.
+func (p *clientPool) dial(ctx context.Context, pc *poolConn, key poolKey, dc *dialCredentials) (*grpc.ClientConn, resourcepb.ResourceClient, error) {
+	opts := append(defaultDialOptions(), dc.opts...)
+	dialCtx, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+	defer cancel()
+	conn, err := grpc.DialContext(dialCtx, key.address, opts...)
+
+	p.m.Lock()
+	defer p.m.Unlock()
+	if err != nil {
+		return nil, nil, p.fail(pc, err)
+	}
+	if pc.removed {
+		conn.Close()
+		return nil, nil, errRemoved(key)
+	}
+	pc.conn = conn
+	pc.client = resourcepb.NewResourceClient(conn)
+	pc.version = dc.version
+	return pc.conn, pc.client, nil
+}
+
+// checked records the result of the health check of the connection in pc.
+func (p *clientPool) checked(pc *poolConn, key poolKey, conn *grpc.ClientConn, err error) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+	if pc.removed || pc.conn != conn {
+		return errRemoved(key)
+	}
+	if err != nil {
+		return p.fail(pc, err)
+	}
+	pc.lastCheck = time.Now()
+	pc.failures = 0
+	pc.lastErr = nil
+	return nil
+}
+
+func errRemoved(key poolKey) error {
+	return fmt.Errorf("backend %s removed from the pool", key.address)
+}
+
+// fail closes the connection and backs off exponentially before the backend
+// is redialed. The caller holds the pool mutex.
+func (p *clientPool) fail(pc *poolConn, err error) error {
+	if pc.conn != nil {
+		pc.conn.Close()
+	}
+	pc.conn = nil
+	pc.client = nil
+	pc.lastCheck = time.Time{}
+	pc.lastErr = err
+	pc.failures++
+
+	d := p.baseBackoff
+	for i := 1; i < pc.failures && d < p.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	pc.retryAfter = time.Now().Add(d)
+	return err
+}
+
+// remove closes the connection and removes it from the pool; a dial or
+// health check of the connection that is in progress is discarded. The
+// caller holds the pool mutex.
+func (p *clientPool) remove(key poolKey, pc *poolConn) {
+	if pc.conn != nil {
+		pc.conn.Close()
+	}
+	pc.conn = nil
+	pc.client = nil
+	pc.removed = true
+	delete(p.conns, key)
+}
+
+// invalidate closes the connections of the register kind.
+func (p *clientPool) invalidate(kind string) {
+	p.m.Lock()
+	defer p.m.Unlock()
+	for key, pc := range p.conns {
+		if key.kind == kind {
+			p.remove(key, pc)
+		}
+	}
+	delete(p.endpoints, kind)
+}
+
+// close closes all pooled connections.
+func (p *clientPool) close() {
+	p.m.Lock()
+	defer p.m.Unlock()
+	for key, pc := range p.conns {
+		p.remove(key, pc)
+	}
+	p.endpoints = make(map[string][]string)
+}
+
+// healthCheck uses the grpc health service of the backend. Backends that do
+// not implement the health service are considered healthy when their
+// connection is ready.
+func healthCheck(ctx context.Context, conn *grpc.ClientConn) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultHealthCheckTimeout)
+	defer cancel()
+	rsp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		if status.Code(err) != codes.Unimplemented {
+			return err
+		}
+		if s := conn.GetState(); s != connectivity.Ready {
+			return fmt.Errorf("connection %s", s)
+		}
+		return nil
+	}
+	if rsp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("backend %s", rsp.GetStatus())
+	}
+	return nil
+}
+
+// defaultDialOptions are the dial options shared by all backends; the grpc
+// connection reconnects with exponential backoff when the backend goes away,
+// and the resource calls are traced and propagate the trace context.
+func defaultDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
+		grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: defaultDialTimeout,
+		}),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMsgSize)),
+	}
+}
+
+func equalEndpoints(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
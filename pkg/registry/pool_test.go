@@ -0,0 +1,74 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// TestPoolGetDoesNotBlockPool verifies that a backend that does not respond to
+// its health check only blocks the callers of its own connection.
+func TestPoolGetDoesNotBlockPool(t *testing.T) {
+	// a listener that accepts connections but never speaks http2
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	p := newClientPool()
+	defer p.close()
+	dc := &dialCredentials{id: "insecure", opts: []grpc.DialOption{grpc.WithInsecure()}}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.get(context.Background(), "ipam", []string{l.Addr().String()}, dc)
+		done <- err
+	}()
+	// wait until the health check of the backend is in progress
+	time.Sleep(200 * time.Millisecond)
+
+	start := time.Now()
+	p.status("ipam")
+	p.invalidate("as")
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("status and invalidate took %s while a backend was dialed", d)
+	}
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("get(...): want error for a backend that does not respond")
+		}
+	case <-time.After(defaultDialTimeout):
+		t.Fatal("get(...): did not return")
+	}
+}
@@ -19,23 +19,24 @@ package registry
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	pkgmetav1 "github.com/yndd/ndd-core/apis/pkg/meta/v1"
 	"github.com/yndd/ndd-runtime/pkg/logging"
-	"github.com/yndd/nddo-grpc/ndd"
-	rclient "github.com/yndd/nddo-grpc/resource/client"
 	"github.com/yndd/nddo-grpc/resource/resourcepb"
 	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
-	orgv1alpha1 "github.com/yndd/nddr-organization/apis/org/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"go.opentelemetry.io/otel/attribute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	nddNamespace = "ndd-system"
+	nddNamespace = pkgmetav1.Namespace
 )
 
 type RegisterKind string
@@ -64,14 +65,91 @@ func (r RegisterKind) String() string {
 	return "unknown"
 }
 
+// RegisterKinds is the set of register kinds known to the registry.
+var RegisterKinds = []RegisterKind{
+	RegisterKindIpam,
+	RegisterKindAs,
+	RegisterKindNetworkInstance,
+	RegisterKindVlan,
+	RegisterKindEndpointGroup,
+}
+
+// DefaultRequiredRegisters are the register kinds that are required when an
+// organization does not specify its required registers; ipam and as serve
+// dynamic grpc services.
+var DefaultRequiredRegisters = []string{
+	RegisterKindIpam.String(),
+	RegisterKindAs.String(),
+}
+
+// RequiredRegisters returns the supplied required registers or the
+// DefaultRequiredRegisters when none are supplied.
+func RequiredRegisters(required []string) []string {
+	if len(required) == 0 {
+		return DefaultRequiredRegisters
+	}
+	return required
+}
+
+// MissingRegisters returns the required register kinds that are not present
+// in the registers.
+func MissingRegisters(registers map[string]string, required []string) []string {
+	missing := make([]string, 0)
+	for _, kind := range required {
+		if _, ok := registers[kind]; !ok {
+			missing = append(missing, kind)
+		}
+	}
+	return missing
+}
+
+// IsRegisterKind returns true if kind is one of the known RegisterKinds.
+func IsRegisterKind(kind string) bool {
+	for _, k := range RegisterKinds {
+		if k.String() == kind {
+			return true
+		}
+	}
+	return false
+}
+
 type registry struct {
 	log logging.Logger
 	// kubernetes
 	client client.Client
+	// cache is the informer cache used for lookups when set
+	cache cache.Cache
+	// reader reads the credential secrets when set
+	reader client.Reader
+	// secrets read with the reader, reused for secretTTL
+	secretMutex sync.Mutex
+	secrets     map[types.NamespacedName]*cachedSecret
+	// organizationNamespace returns the namespace of the organization of the
+	// registers in a namespace
+	organizationNamespace func(string) string
+	// backends per register kind
+	backends map[string]*Backend
+	// backendReader lists the backend services when set
+	backendReader client.Reader
+	// credentials used to connect to the backends
+	credentials *Credentials
+	// pool of backend connections
+	pool *clientPool
+	// watchers of register changes
+	watchMutex sync.Mutex
+	watchers   map[*watcher]struct{}
 }
 
 func New(opts ...Option) Registry {
-	s := &registry{}
+	s := &registry{
+		backends:    DefaultBackends(nddNamespace),
+		credentials: DefaultCredentials(),
+		pool:        newClientPool(),
+		secrets:     make(map[types.NamespacedName]*cachedSecret),
+		organizationNamespace: func(namespace string) string {
+			return namespace
+		},
+	}
 
 	for _, opt := range opts {
 		opt(s)
@@ -88,143 +166,175 @@ func (s *registry) WithClient(c client.Client) {
 	s.client = c
 }
 
+func (s *registry) WithBackends(b map[string]*Backend) {
+	s.backends = b
+	// connections to the previous backends are no longer valid
+	s.pool.close()
+}
+
+func (s *registry) WithBackendReader(r client.Reader) {
+	s.backendReader = r
+}
+
+func (s *registry) WithCredentials(c *Credentials) {
+	s.credentials = c
+}
+
+func (s *registry) WithCache(c cache.Cache) {
+	s.cache = c
+}
+
+func (s *registry) WithAPIReader(r client.Reader) {
+	s.reader = r
+}
+
+func (s *registry) WithOrganizationNamespace(fn func(string) string) {
+	s.organizationNamespace = fn
+}
+
 func (r *registry) GetRegisterName(organizationName string, deploymentName string) string {
+	return registerName(organizationName, deploymentName)
+}
+
+func registerName(organizationName string, deploymentName string) string {
 	if deploymentName == "" {
 		return organizationName
 	}
 	return strings.Join([]string{organizationName, deploymentName}, ".")
 }
 
-func (r *registry) GetRegister(ctx context.Context, namespace, registerName string) (map[string]string, error) {
-	// critical registers are ipam and as right now since they server dynamic
-	// grpc services
-	criticalRegisters := []string{
-		RegisterKindIpam.String(),
-		RegisterKindAs.String(),
+func (r *registry) GetRegister(ctx context.Context, namespace, registerName string) (registers map[string]string, err error) {
+	ctx, span := startSpan(ctx, "GetRegister",
+		attribute.String(attrNamespace, namespace),
+		attribute.String(attrRegisterName, registerName))
+	defer func() { endSpan(span, err) }()
+	defer func(start time.Time) { r.observeLookup("GetRegister", start, err) }(time.Now())
+	var required []string
+	dep, org, err := r.getRegisterOwner(ctx, namespace, registerName)
+	if err != nil {
+		return nil, err
 	}
-
-	var registers map[string]string
-	switch len(strings.Split(registerName, ".")) {
-	case 2:
-		dep := &orgv1alpha1.Deployment{}
-		if err := r.client.Get(ctx, types.NamespacedName{
-			Namespace: namespace,
-			Name:      registerName,
-		}, dep); err != nil {
-			return nil, err
-		}
+	if dep != nil {
 		registers = dep.GetStateRegister()
-
-	case 1:
-		org := &orgv1alpha1.Organization{}
-		if err := r.client.Get(ctx, types.NamespacedName{
-			Namespace: namespace,
-			Name:      registerName,
-		}, org); err != nil {
-			return nil, err
-		}
-
+		required = dep.GetStateRequiredRegisters()
+	} else {
 		registers = org.GetStateRegister()
-	default:
-		return nil, fmt.Errorf("wrong input in get register %s", registerName)
+		required = org.GetStateRequiredRegisters()
 	}
-	for _, register := range criticalRegisters {
-		if _, ok := registers[register]; !ok {
-			return nil, fmt.Errorf("critical register %s not found in registry", register)
-		}
+	// the required registers are resolved by the controllers per organization
+	// and deployment kind
+	if missing := MissingRegisters(registers, RequiredRegisters(required)); len(missing) > 0 {
+		return nil, &ErrCriticalRegisterMissing{Kind: missing[0]}
 	}
 	return registers, nil
 }
 
-func (r *registry) GetAddressAllocationStrategy(ctx context.Context, namespace, registerName string) (*nddov1.AddressAllocationStrategy, error) {
-	switch len(strings.Split(registerName, ".")) {
-	case 2:
-		dep := &orgv1alpha1.Deployment{}
-		if err := r.client.Get(ctx, types.NamespacedName{
-			Namespace: namespace,
-			Name:      registerName,
-		}, dep); err != nil {
-			return nil, err
-		}
+func (r *registry) GetAddressAllocationStrategy(ctx context.Context, namespace, registerName string) (aas *nddov1.AddressAllocationStrategy, err error) {
+	ctx, span := startSpan(ctx, "GetAddressAllocationStrategy",
+		attribute.String(attrNamespace, namespace),
+		attribute.String(attrRegisterName, registerName))
+	defer func() { endSpan(span, err) }()
+	defer func(start time.Time) { r.observeLookup("GetAddressAllocationStrategy", start, err) }(time.Now())
+	dep, org, err := r.getRegisterOwner(ctx, namespace, registerName)
+	if err != nil {
+		return nil, err
+	}
+	if dep != nil {
 		return dep.GetStateAddressAllocationStrategy(), nil
-
-	case 1:
-		org := &orgv1alpha1.Organization{}
-		if err := r.client.Get(ctx, types.NamespacedName{
-			Namespace: namespace,
-			Name:      registerName,
-		}, org); err != nil {
-			return nil, err
-		}
-
-		return org.GetStateAddressAllocationStrategy(), nil
-	default:
-		return nil, fmt.Errorf("wrong input in get register %s", registerName)
 	}
+	return org.GetStateAddressAllocationStrategy(), nil
 }
 
-func (r *registry) GetRegistryClient(ctx context.Context, registerName string) (resourcepb.ResourceClient, error) {
-	registers := map[string]string{
-		RegisterKindIpam.String():            "nddr-ipam",
-		RegisterKindAs.String():              "nddr-aspool",
-		RegisterKindNetworkInstance.String(): "nddr-ni-registry",
+// getRegisterOwner returns the deployment or, when no deployment exists with
+// the register name, the organization that owns the register. A deployment is
+// resolved by its object name; its organization is given by the explicit
+// organization reference and not derived from the name. The organization is
+// looked up in the organization namespace of the namespace. When the registry
+// has an informer cache the owner is looked up in the cache.
+func (r *registry) getRegisterOwner(ctx context.Context, namespace, registerName string) (orgv1alpha2.Dp, orgv1alpha2.Org, error) {
+	if registerName == "" {
+		return nil, nil, wrapError(ErrInvalidRegisterName, fmt.Errorf("wrong input in get register %s", registerName))
 	}
-
-	if _, ok := registers[registerName]; !ok {
-		return nil, fmt.Errorf("wrong register request, name not found: %s", registerName)
+	if r.cache != nil {
+		return r.getCachedRegisterOwner(ctx, namespace, registerName)
 	}
-	registerMatch := registers[registerName]
-
-	pods := &corev1.PodList{}
-	opts := []client.ListOption{
-		client.InNamespace(nddNamespace),
+	dep := &orgv1alpha2.Deployment{}
+	err := r.client.Get(ctx, types.NamespacedName{
+		Namespace: namespace,
+		Name:      registerName,
+	}, dep)
+	if err == nil {
+		return dep, nil, nil
 	}
-	if err := r.client.List(ctx, pods, opts...); err != nil {
-		return nil, err
+	if !apierrors.IsNotFound(err) {
+		return nil, nil, err
 	}
 
-	var podname string
-	found := false
-	for _, pod := range pods.Items {
-		podName := pod.GetName()
-		//r.log.Debug("pod", "podname", podName)
-		if strings.Contains(podName, registerMatch) {
-			podname = podName
-			found = true
-			break
-		}
-	}
-	if !found {
-		return nil, fmt.Errorf("no pod that matches %s, %s", registerName, registerMatch)
+	org := &orgv1alpha2.Organization{}
+	if err := r.client.Get(ctx, types.NamespacedName{
+		Namespace: r.organizationNamespace(namespace),
+		Name:      registerName,
+	}, org); err != nil {
+		return nil, nil, wrapNotFound(err)
 	}
+	return nil, org, nil
+}
 
-	return getResourceClient(ctx, getGrpcServerName(podname))
-
+// GetRegistryClient returns a pooled client to a healthy backend of the
+// register kind, using the credentials of the registry.
+func (r *registry) GetRegistryClient(ctx context.Context, registerName string) (rc resourcepb.ResourceClient, err error) {
+	ctx, span := startSpan(ctx, "GetRegistryClient",
+		attribute.String(attrRegisterKind, registerName))
+	defer func() { endSpan(span, err) }()
+	return r.getRegistryClient(ctx, registerName, r.credentials)
 }
 
-func getGrpcServerName(podName string) string {
-	var newName string
-	for i, s := range strings.Split(podName, "-") {
-		if i == 0 {
-			newName = s
-		} else if i <= (len(strings.Split(podName, "-")) - 3) {
-			newName += "-" + s
+// GetOrganizationRegistryClient returns a pooled client to a healthy backend
+// of the register kind, using the registry credentials of the organization
+// that owns the register, or the credentials of the registry when the
+// organization has none.
+func (r *registry) GetOrganizationRegistryClient(ctx context.Context, namespace, registerName, registerKind string) (rc resourcepb.ResourceClient, err error) {
+	ctx, span := startSpan(ctx, "GetOrganizationRegistryClient",
+		attribute.String(attrNamespace, namespace),
+		attribute.String(attrRegisterName, registerName),
+		attribute.String(attrRegisterKind, registerKind))
+	defer func() { endSpan(span, err) }()
+	dep, org, err := r.getRegisterOwner(ctx, namespace, registerName)
+	if err != nil {
+		return nil, err
+	}
+	if dep != nil {
+		org = &orgv1alpha2.Organization{}
+		if err := r.client.Get(ctx, types.NamespacedName{
+			Namespace: r.organizationNamespace(namespace),
+			Name:      dep.GetOrganizationName(),
+		}, org); err != nil {
+			return nil, wrapNotFound(err)
 		}
 	}
-	return pkgmetav1.PrefixGnmiService + "-" + newName + "." + pkgmetav1.NamespaceLocalK8sDNS + strconv.Itoa((pkgmetav1.GnmiServerPort))
+	creds := OrganizationCredentials(org)
+	if creds == nil {
+		creds = r.credentials
+	}
+	return r.getRegistryClient(ctx, registerKind, creds)
 }
 
-func getResourceClient(ctx context.Context, grpcserver string) (resourcepb.ResourceClient, error) {
-	cfg := &ndd.Config{
-		Address:  grpcserver,
-		Username: "admin",
-		Password: "admin",
-		//Timeout:    10 * time.Second,
-		SkipVerify: true,
-		Insecure:   true,
-		TLSCA:      "", //TODO TLS
-		TLSCert:    "",
-		TLSKey:     "",
+func (r *registry) getRegistryClient(ctx context.Context, registerKind string, creds *Credentials) (resourcepb.ResourceClient, error) {
+	endpoints, err := r.GetRegistryEndpoints(ctx, registerKind)
+	if err != nil {
+		return nil, err
 	}
-	return rclient.NewClient(ctx, cfg)
+	dc, err := r.getDialCredentials(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	ctx, span := startSpan(ctx, "pool.get", attribute.String(attrRegisterKind, registerKind))
+	rc, err := r.pool.get(ctx, registerKind, endpoints, dc)
+	endSpan(span, err)
+	return rc, err
+}
+
+// Close closes the pooled backend connections.
+func (r *registry) Close() {
+	r.pool.close()
 }
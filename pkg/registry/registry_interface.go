@@ -22,6 +22,7 @@ import (
 	"github.com/yndd/ndd-runtime/pkg/logging"
 	"github.com/yndd/nddo-grpc/resource/resourcepb"
 	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -41,11 +42,74 @@ func WithClient(c client.Client) Option {
 	}
 }
 
+// WithBackends specifies how the registry backends are discovered per
+// register kind.
+func WithBackends(b map[string]*Backend) Option {
+	return func(s Registry) {
+		s.WithBackends(b)
+	}
+}
+
+// WithBackendReader specifies the reader used to list the services and
+// endpoint slices of the registry backends, such that the registry does not
+// need cluster-wide informers for them. The reader is typically a cache
+// restricted to the backend namespace. Without it the client is used.
+func WithBackendReader(r client.Reader) Option {
+	return func(s Registry) {
+		s.WithBackendReader(r)
+	}
+}
+
+// WithCredentials specifies the credentials used to connect to the registry
+// backends, when the organization of a register does not specify its own.
+func WithCredentials(c *Credentials) Option {
+	return func(s Registry) {
+		s.WithCredentials(c)
+	}
+}
+
+// WithAPIReader specifies the uncached reader used to read the credential
+// secrets, such that the registry does not need a cluster-wide secret
+// informer. Without it the secrets are read with the client.
+func WithAPIReader(r client.Reader) Option {
+	return func(s Registry) {
+		s.WithAPIReader(r)
+	}
+}
+
+// WithOrganizationNamespace specifies the namespace of the organization of
+// the registers in a namespace; by default it is the namespace of the
+// register.
+func WithOrganizationNamespace(fn func(string) string) Option {
+	return func(s Registry) {
+		s.WithOrganizationNamespace(fn)
+	}
+}
+
+// WithCache specifies the informer cache used to look up registers; the cache
+// must be indexed with IndexRegisters.
+func WithCache(c cache.Cache) Option {
+	return func(s Registry) {
+		s.WithCache(c)
+	}
+}
+
 type Registry interface {
 	WithLogger(logging.Logger)
 	WithClient(client.Client)
+	WithBackends(map[string]*Backend)
+	WithBackendReader(client.Reader)
+	WithCredentials(*Credentials)
+	WithCache(cache.Cache)
+	WithAPIReader(client.Reader)
+	WithOrganizationNamespace(func(string) string)
 	GetRegisterName(string, string) string
 	GetRegister(context.Context, string, string) (map[string]string, error)
 	GetAddressAllocationStrategy(context.Context, string, string) (*nddov1.AddressAllocationStrategy, error)
+	Watch(ctx context.Context, namespace, registerName string) (<-chan RegisterEvent, error)
+	GetRegistryEndpoints(ctx context.Context, registerKind string) ([]string, error)
 	GetRegistryClient(ctx context.Context, registerName string) (resourcepb.ResourceClient, error)
+	GetOrganizationRegistryClient(ctx context.Context, namespace, registerName, registerKind string) (resourcepb.ResourceClient, error)
+	GetBackendStatus(ctx context.Context) []BackendStatus
+	Close()
 }
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/pkg/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetRegisterOrganizationNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	register := map[string]string{"ipam": "nokia.default", "as": "nokia.default"}
+	org := &orgv1alpha2.Organization{
+		ObjectMeta: metav1.ObjectMeta{Name: "nokia", Namespace: "orgs"},
+		Status: orgv1alpha2.OrganizationStatus{Organization: &orgv1alpha2.NddrOrganization{
+			State: &orgv1alpha2.NddrOrgDeploymentState{},
+		}},
+	}
+	org.SetStatus("up")
+	org.SetStateRegister(register)
+
+	cases := map[string]struct {
+		organizationNamespace func(string) string
+		namespace             string
+		want                  map[string]string
+		wantErr               bool
+	}{
+		"OwnNamespace": {
+			namespace: "orgs",
+			want:      register,
+		},
+		"OtherNamespace": {
+			namespace: "tenant",
+			wantErr:   true,
+		},
+		"ClusterOrganizationNamespace": {
+			organizationNamespace: func(string) string { return "orgs" },
+			namespace:             "tenant",
+			want:                  register,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			opts := []registry.Option{
+				registry.WithClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(org.DeepCopy()).Build()),
+			}
+			if tc.organizationNamespace != nil {
+				opts = append(opts, registry.WithOrganizationNamespace(tc.organizationNamespace))
+			}
+			r := registry.New(opts...)
+			defer r.Close()
+
+			got, err := r.GetRegister(context.Background(), tc.namespace, "nokia")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("GetRegister(...): unexpected error %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("GetRegister(...): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registrytest provides an in-memory registry.Registry for the unit
+// tests of the controllers that consume the registry.
+package registrytest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/yndd/ndd-runtime/pkg/logging"
+	"github.com/yndd/nddo-grpc/resource/resourcepb"
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/pkg/registry"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Methods of the registry for which errors can be injected
+const (
+	MethodGetRegister                   = "GetRegister"
+	MethodGetAddressAllocationStrategy  = "GetAddressAllocationStrategy"
+	MethodWatch                         = "Watch"
+	MethodGetRegistryEndpoints          = "GetRegistryEndpoints"
+	MethodGetRegistryClient             = "GetRegistryClient"
+	MethodGetOrganizationRegistryClient = "GetOrganizationRegistryClient"
+)
+
+var _ registry.Registry = &Registry{}
+
+type key struct {
+	namespace    string
+	registerName string
+}
+
+// entry is the effective state of a register
+type entry struct {
+	register map[string]string
+	aas      *nddov1.AddressAllocationStrategy
+	required []string
+}
+
+// Registry is an in-memory registry.Registry. It is seeded with the effective
+// registers of organizations and deployments and returns in-process resource
+// clients per register kind.
+type Registry struct {
+	m         sync.Mutex
+	entries   map[key]*entry
+	endpoints map[string][]string
+	clients   map[string]*ResourceClient
+	errs      map[string]error
+	watchers  map[*watcher]struct{}
+}
+
+// watcher delivers the register events of a key to a channel; as in the
+// registry, events are queued such that a slow consumer neither blocks the
+// registry nor loses events.
+type watcher struct {
+	key key
+
+	m      sync.Mutex
+	queue  []registry.RegisterEvent
+	notify chan struct{}
+	ch     chan registry.RegisterEvent
+}
+
+// New returns an empty in-memory Registry.
+func New() *Registry {
+	return &Registry{
+		entries:   make(map[key]*entry),
+		endpoints: make(map[string][]string),
+		clients:   make(map[string]*ResourceClient),
+		errs:      make(map[string]error),
+		watchers:  make(map[*watcher]struct{}),
+	}
+}
+
+// WithOrganization seeds the registry with the register, address allocation
+// strategy and required registers in the status of the organization.
+func (r *Registry) WithOrganization(org orgv1alpha2.Org) *Registry {
+	r.set(key{namespace: org.GetNamespace(), registerName: org.GetName()}, &entry{
+		register: org.GetStateRegister(),
+		aas:      org.GetStateAddressAllocationStrategy(),
+		required: org.GetStateRequiredRegisters(),
+	})
+	return r
+}
+
+// WithDeployment seeds the registry with the register, address allocation
+// strategy and required registers in the status of the deployment.
+func (r *Registry) WithDeployment(dep orgv1alpha2.Dp) *Registry {
+	name := dep.GetName()
+	if orgName := dep.GetOrganizationName(); orgName != "" {
+		name = r.GetRegisterName(orgName, dep.GetDeploymentName())
+	}
+	r.set(key{namespace: dep.GetNamespace(), registerName: name}, &entry{
+		register: dep.GetStateRegister(),
+		aas:      dep.GetStateAddressAllocationStrategy(),
+		required: dep.GetStateRequiredRegisters(),
+	})
+	return r
+}
+
+// WithRegister seeds the registry with the register of the register name.
+func (r *Registry) WithRegister(namespace, registerName string, register map[string]string) *Registry {
+	r.m.Lock()
+	e := r.copyEntry(key{namespace: namespace, registerName: registerName})
+	r.m.Unlock()
+	e.register = register
+	r.set(key{namespace: namespace, registerName: registerName}, e)
+	return r
+}
+
+// WithAddressAllocationStrategy seeds the registry with the address
+// allocation strategy of the register name.
+func (r *Registry) WithAddressAllocationStrategy(namespace, registerName string, aas *nddov1.AddressAllocationStrategy) *Registry {
+	r.m.Lock()
+	e := r.copyEntry(key{namespace: namespace, registerName: registerName})
+	r.m.Unlock()
+	e.aas = aas
+	r.set(key{namespace: namespace, registerName: registerName}, e)
+	return r
+}
+
+// WithRequiredRegisters sets the required registers of the register name;
+// when none are set the registry.DefaultRequiredRegisters are required.
+func (r *Registry) WithRequiredRegisters(namespace, registerName string, required []string) *Registry {
+	r.m.Lock()
+	e := r.copyEntry(key{namespace: namespace, registerName: registerName})
+	r.m.Unlock()
+	e.required = required
+	r.set(key{namespace: namespace, registerName: registerName}, e)
+	return r
+}
+
+// WithEndpoints sets the backend endpoints of the register kind.
+func (r *Registry) WithEndpoints(registerKind string, endpoints ...string) *Registry {
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.endpoints[registerKind] = endpoints
+	return r
+}
+
+// WithError injects an error that is returned by the method until it is
+// cleared with a nil error.
+func (r *Registry) WithError(method string, err error) *Registry {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if err == nil {
+		delete(r.errs, method)
+		return r
+	}
+	r.errs[method] = err
+	return r
+}
+
+// DeleteRegister removes the register name from the registry.
+func (r *Registry) DeleteRegister(namespace, registerName string) {
+	r.set(key{namespace: namespace, registerName: registerName}, nil)
+}
+
+// ResourceClient returns the in-process resource client of the register
+// kind, as returned by GetRegistryClient.
+func (r *Registry) ResourceClient(registerKind string) *ResourceClient {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.resourceClient(registerKind)
+}
+
+func (r *Registry) resourceClient(registerKind string) *ResourceClient {
+	c, ok := r.clients[registerKind]
+	if !ok {
+		c = NewResourceClient()
+		r.clients[registerKind] = c
+	}
+	return c
+}
+
+// copyEntry returns a copy of the entry of the key or a new entry
+func (r *Registry) copyEntry(k key) *entry {
+	if e, ok := r.entries[k]; ok {
+		c := *e
+		return &c
+	}
+	return &entry{}
+}
+
+// set stores the entry of the key, or deletes it when nil, and notifies the
+// watchers when the effective state changed.
+func (r *Registry) set(k key, e *entry) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	old := r.entries[k]
+	if e == nil {
+		delete(r.entries, k)
+	} else {
+		r.entries[k] = e
+	}
+
+	ev := registry.RegisterEvent{Namespace: k.namespace, RegisterName: k.registerName}
+	if old != nil {
+		ev.OldRegister = old.register
+		ev.OldAddressAllocationStrategy = old.aas
+	}
+	if e != nil {
+		ev.NewRegister = e.register
+		ev.NewAddressAllocationStrategy = e.aas
+	}
+	if old != nil && e != nil &&
+		reflect.DeepEqual(ev.OldRegister, ev.NewRegister) &&
+		reflect.DeepEqual(ev.OldAddressAllocationStrategy, ev.NewAddressAllocationStrategy) {
+		return
+	}
+	for w := range r.watchers {
+		if w.key != k {
+			continue
+		}
+		w.m.Lock()
+		w.queue = append(w.queue, ev)
+		w.m.Unlock()
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (r *Registry) err(method string) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return r.errs[method]
+}
+
+func (r *Registry) WithLogger(log logging.Logger) {}
+
+func (r *Registry) WithClient(c client.Client) {}
+
+func (r *Registry) WithBackends(b map[string]*registry.Backend) {}
+
+func (r *Registry) WithCredentials(c *registry.Credentials) {}
+
+func (r *Registry) WithCache(c cache.Cache) {}
+
+func (r *Registry) WithBackendReader(c client.Reader) {}
+
+func (r *Registry) WithAPIReader(c client.Reader) {}
+
+func (r *Registry) WithOrganizationNamespace(fn func(string) string) {}
+
+func (r *Registry) GetRegisterName(organizationName string, deploymentName string) string {
+	if deploymentName == "" {
+		return organizationName
+	}
+	return organizationName + "." + deploymentName
+}
+
+func (r *Registry) GetRegister(ctx context.Context, namespace, registerName string) (map[string]string, error) {
+	if err := r.err(MethodGetRegister); err != nil {
+		return nil, err
+	}
+	e, err := r.get(namespace, registerName)
+	if err != nil {
+		return nil, err
+	}
+	if missing := registry.MissingRegisters(e.register, registry.RequiredRegisters(e.required)); len(missing) > 0 {
+		return nil, &registry.ErrCriticalRegisterMissing{Kind: missing[0]}
+	}
+	return e.register, nil
+}
+
+func (r *Registry) GetAddressAllocationStrategy(ctx context.Context, namespace, registerName string) (*nddov1.AddressAllocationStrategy, error) {
+	if err := r.err(MethodGetAddressAllocationStrategy); err != nil {
+		return nil, err
+	}
+	e, err := r.get(namespace, registerName)
+	if err != nil {
+		return nil, err
+	}
+	return e.aas, nil
+}
+
+func (r *Registry) get(namespace, registerName string) (*entry, error) {
+	if registerName == "" {
+		return nil, fmt.Errorf("%w: wrong input in get register %s", registry.ErrInvalidRegisterName, registerName)
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	e, ok := r.entries[key{namespace: namespace, registerName: registerName}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", registry.ErrNotFound, namespace, registerName)
+	}
+	return e, nil
+}
+
+// Watch streams the changes of the register name until the context is done.
+// The events are queued and delivered in order; none are dropped.
+func (r *Registry) Watch(ctx context.Context, namespace, registerName string) (<-chan registry.RegisterEvent, error) {
+	if err := r.err(MethodWatch); err != nil {
+		return nil, err
+	}
+	if registerName == "" {
+		return nil, fmt.Errorf("%w: wrong input in watch register %s", registry.ErrInvalidRegisterName, registerName)
+	}
+	w := &watcher{
+		key:    key{namespace: namespace, registerName: registerName},
+		notify: make(chan struct{}, 1),
+		ch:     make(chan registry.RegisterEvent),
+	}
+	r.m.Lock()
+	r.watchers[w] = struct{}{}
+	r.m.Unlock()
+
+	go func() {
+		defer func() {
+			r.m.Lock()
+			delete(r.watchers, w)
+			r.m.Unlock()
+			close(w.ch)
+		}()
+		for {
+			w.m.Lock()
+			if len(w.queue) == 0 {
+				w.m.Unlock()
+				select {
+				case <-ctx.Done():
+					return
+				case <-w.notify:
+				}
+				continue
+			}
+			ev := w.queue[0]
+			w.queue = w.queue[1:]
+			w.m.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case w.ch <- ev:
+			}
+		}
+	}()
+	return w.ch, nil
+}
+
+// GetRegistryEndpoints returns the endpoints set WithEndpoints, or a single
+// in-process endpoint when none are set.
+func (r *Registry) GetRegistryEndpoints(ctx context.Context, registerKind string) ([]string, error) {
+	if err := r.err(MethodGetRegistryEndpoints); err != nil {
+		return nil, err
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	endpoints, ok := r.endpoints[registerKind]
+	if !ok {
+		return []string{"inprocess:" + registerKind}, nil
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%w: no ready endpoint for register %s", registry.ErrBackendUnavailable, registerKind)
+	}
+	endpoints = append([]string(nil), endpoints...)
+	sort.Strings(endpoints)
+	return endpoints, nil
+}
+
+func (r *Registry) GetRegistryClient(ctx context.Context, registerName string) (resourcepb.ResourceClient, error) {
+	if err := r.err(MethodGetRegistryClient); err != nil {
+		return nil, err
+	}
+	if _, err := r.GetRegistryEndpoints(ctx, registerName); err != nil {
+		return nil, err
+	}
+	return r.ResourceClient(registerName), nil
+}
+
+func (r *Registry) GetOrganizationRegistryClient(ctx context.Context, namespace, registerName, registerKind string) (resourcepb.ResourceClient, error) {
+	if err := r.err(MethodGetOrganizationRegistryClient); err != nil {
+		return nil, err
+	}
+	if _, err := r.get(namespace, registerName); err != nil {
+		return nil, err
+	}
+	return r.GetRegistryClient(ctx, registerKind)
+}
+
+// GetBackendStatus returns the status of the backends of the known register
+// kinds; a backend is unhealthy when GetRegistryClient fails for its kind.
+func (r *Registry) GetBackendStatus(ctx context.Context) []registry.BackendStatus {
+	statuses := make([]registry.BackendStatus, 0, len(registry.RegisterKinds))
+	for _, k := range registry.RegisterKinds {
+		kind := k.String()
+		s := registry.BackendStatus{Kind: kind}
+		if _, err := r.GetRegistryClient(ctx, kind); err != nil {
+			s.Error = err.Error()
+		} else {
+			s.Healthy = true
+		}
+		if endpoints, err := r.GetRegistryEndpoints(ctx, kind); err == nil {
+			for _, address := range endpoints {
+				s.Endpoints = append(s.Endpoints, registry.EndpointStatus{Address: address, Connected: s.Healthy})
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Kind < statuses[j].Kind
+	})
+	return statuses
+}
+
+func (r *Registry) Close() {}
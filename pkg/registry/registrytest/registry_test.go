@@ -0,0 +1,183 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrytest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/yndd/ndd-runtime/pkg/utils"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/pkg/registry"
+	"github.com/yndd/nddr-organization/pkg/registry/registrytest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func organization(name string, register map[string]string, required []string) *orgv1alpha2.Organization {
+	org := &orgv1alpha2.Organization{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status: orgv1alpha2.OrganizationStatus{Organization: &orgv1alpha2.NddrOrganization{
+			State: &orgv1alpha2.NddrOrgDeploymentState{},
+		}},
+	}
+	org.SetStatus("up")
+	org.SetStateRegister(register)
+	org.SetStateRequiredRegisters(required)
+	return org
+}
+
+func deployment(orgName, name string, register map[string]string, required []string) *orgv1alpha2.Deployment {
+	dep := &orgv1alpha2.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: orgName + "." + name, Namespace: "default"},
+		Spec: orgv1alpha2.DeploymentSpec{Deployment: &orgv1alpha2.OrgDeployment{
+			OrganizationRef: utils.StringPtr(orgName),
+		}},
+		Status: orgv1alpha2.DeploymentStatus{Deployment: &orgv1alpha2.NddrOrgDeployment{
+			State: &orgv1alpha2.NddrOrgDeploymentState{},
+		}},
+	}
+	dep.SetStatus("up")
+	dep.SetStateRegister(register)
+	dep.SetStateRequiredRegisters(required)
+	return dep
+}
+
+// TestGetRegisterMatchesRegistry verifies that the fake returns the same
+// registers and errors as the registry for the same organizations and
+// deployments.
+func TestGetRegisterMatchesRegistry(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	complete := map[string]string{"ipam": "nokia.default", "as": "nokia.default"}
+
+	cases := map[string]struct {
+		orgs         []*orgv1alpha2.Organization
+		deps         []*orgv1alpha2.Deployment
+		registerName string
+		want         map[string]string
+		wantErr      error
+	}{
+		"Organization": {
+			orgs:         []*orgv1alpha2.Organization{organization("nokia", complete, nil)},
+			registerName: "nokia",
+			want:         complete,
+		},
+		"Deployment": {
+			orgs: []*orgv1alpha2.Organization{organization("nokia", complete, nil)},
+			deps: []*orgv1alpha2.Deployment{
+				deployment("nokia", "region1", map[string]string{"ipam": "nokia.region1", "as": "nokia.default"}, nil),
+			},
+			registerName: "nokia.region1",
+			want:         map[string]string{"ipam": "nokia.region1", "as": "nokia.default"},
+		},
+		"NotFound": {
+			orgs:         []*orgv1alpha2.Organization{organization("nokia", complete, nil)},
+			registerName: "nokia.region1",
+			wantErr:      registry.ErrNotFound,
+		},
+		"InvalidRegisterName": {
+			wantErr: registry.ErrInvalidRegisterName,
+		},
+		"CriticalRegisterMissing": {
+			orgs:         []*orgv1alpha2.Organization{organization("nokia", map[string]string{"ipam": "nokia.default"}, nil)},
+			registerName: "nokia",
+			wantErr:      &registry.ErrCriticalRegisterMissing{Kind: "as"},
+		},
+		"RequiredRegisterMissing": {
+			orgs: []*orgv1alpha2.Organization{organization("nokia", complete, nil)},
+			deps: []*orgv1alpha2.Deployment{
+				deployment("nokia", "region1", complete, []string{"ipam", "vlan"}),
+			},
+			registerName: "nokia.region1",
+			wantErr:      &registry.ErrCriticalRegisterMissing{Kind: "vlan"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fakeRegistry := registrytest.New()
+			objs := make([]client.Object, 0)
+			for _, org := range tc.orgs {
+				fakeRegistry.WithOrganization(org)
+				objs = append(objs, org.DeepCopy())
+			}
+			for _, dep := range tc.deps {
+				fakeRegistry.WithDeployment(dep)
+				objs = append(objs, dep.DeepCopy())
+			}
+			r := registry.New(registry.WithClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()))
+			defer r.Close()
+
+			for impl, reg := range map[string]registry.Registry{"registry": r, "registrytest": fakeRegistry} {
+				got, err := reg.GetRegister(context.Background(), "default", tc.registerName)
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("%s GetRegister(...): want error %v, got %v", impl, tc.wantErr, err)
+				}
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("%s GetRegister(...): -want, +got:\n%s", impl, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchDeliversAllEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r := registrytest.New()
+	ch, err := r.Watch(ctx, "default", "nokia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// more events than a watch channel buffers are queued before they are
+	// read
+	names := []string{"a", "b", "c"}
+	const n = 300
+	for i := 0; i < n; i++ {
+		r.WithRegister("default", "nokia", map[string]string{"ipam": names[i%len(names)]})
+	}
+	for i := 0; i < n; i++ {
+		select {
+		case ev := <-ch:
+			if got, want := ev.NewRegister["ipam"], names[i%len(names)]; got != want {
+				t.Fatalf("event %d: want register %s, got %s", i, want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %d: not delivered", i)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Errorf("Watch(...): want the channel closed after cancel")
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Watch(...): channel not closed after cancel")
+	}
+}
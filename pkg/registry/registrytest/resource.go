@@ -0,0 +1,119 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrytest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yndd/nddo-grpc/resource/resourcepb"
+	"google.golang.org/grpc"
+)
+
+// ResourceFn handles a resource request of the in-process ResourceClient.
+type ResourceFn func(ctx context.Context, req *resourcepb.Request) (*resourcepb.Reply, error)
+
+// ResourceClient is an in-process resourcepb.ResourceClient. By default it
+// keeps the allocations in memory: an allocation replies with the data of the
+// request selector and is returned by a get until it is deallocated. The
+// default behaviour can be replaced per method and all requests are recorded.
+type ResourceClient struct {
+	m           sync.Mutex
+	allocations map[string]*resourcepb.Reply
+	requests    []*resourcepb.Request
+
+	GetFn     ResourceFn
+	AllocFn   ResourceFn
+	DeAllocFn ResourceFn
+}
+
+var _ resourcepb.ResourceClient = &ResourceClient{}
+
+// NewResourceClient returns an in-process ResourceClient without allocations.
+func NewResourceClient() *ResourceClient {
+	return &ResourceClient{
+		allocations: make(map[string]*resourcepb.Reply),
+	}
+}
+
+// Requests returns the requests received by the client.
+func (c *ResourceClient) Requests() []*resourcepb.Request {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return append([]*resourcepb.Request(nil), c.requests...)
+}
+
+// Allocation returns the allocation of the resource name, if any.
+func (c *ResourceClient) Allocation(namespace, resourceName string) (*resourcepb.Reply, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	reply, ok := c.allocations[allocationKey(namespace, resourceName)]
+	return reply, ok
+}
+
+func (c *ResourceClient) ResourceGet(ctx context.Context, in *resourcepb.Request, opts ...grpc.CallOption) (*resourcepb.Reply, error) {
+	if fn := c.record(in, c.GetFn); fn != nil {
+		return fn(ctx, in)
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if reply, ok := c.allocations[allocationKey(in.GetNamespace(), in.GetResourceName())]; ok {
+		return reply, nil
+	}
+	return &resourcepb.Reply{Ready: false, Timestamp: time.Now().UnixNano()}, nil
+}
+
+func (c *ResourceClient) ResourceAlloc(ctx context.Context, in *resourcepb.Request, opts ...grpc.CallOption) (*resourcepb.Reply, error) {
+	if fn := c.record(in, c.AllocFn); fn != nil {
+		return fn(ctx, in)
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	data := make(map[string]*resourcepb.TypedValue)
+	for k, v := range in.GetAlloc().GetSelector() {
+		data[k] = &resourcepb.TypedValue{Value: &resourcepb.TypedValue_StringVal{StringVal: v}}
+	}
+	if prefix := in.GetAlloc().GetIpPrefix(); prefix != "" {
+		data["ip-prefix"] = &resourcepb.TypedValue{Value: &resourcepb.TypedValue_StringVal{StringVal: prefix}}
+	}
+	reply := &resourcepb.Reply{Ready: true, Timestamp: time.Now().UnixNano(), Data: data}
+	c.allocations[allocationKey(in.GetNamespace(), in.GetResourceName())] = reply
+	return reply, nil
+}
+
+func (c *ResourceClient) ResourceDeAlloc(ctx context.Context, in *resourcepb.Request, opts ...grpc.CallOption) (*resourcepb.Reply, error) {
+	if fn := c.record(in, c.DeAllocFn); fn != nil {
+		return fn(ctx, in)
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	delete(c.allocations, allocationKey(in.GetNamespace(), in.GetResourceName()))
+	return &resourcepb.Reply{Ready: true, Timestamp: time.Now().UnixNano()}, nil
+}
+
+// record records the request and returns the method override, if any
+func (c *ResourceClient) record(in *resourcepb.Request, fn ResourceFn) ResourceFn {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.requests = append(c.requests, in)
+	return fn
+}
+
+func allocationKey(namespace, resourceName string) string {
+	return namespace + "/" + resourceName
+}
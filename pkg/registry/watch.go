@@ -0,0 +1,229 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	nddov1 "github.com/yndd/nddo-runtime/apis/common/v1"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"go.opentelemetry.io/otel/attribute"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegisterEvent is a change of the effective register or address allocation
+// strategy of an organization or deployment. The old values are nil when the
+// register is created and the new values are nil when it is deleted.
+type RegisterEvent struct {
+	Namespace    string
+	RegisterName string
+
+	OldRegister                  map[string]string
+	NewRegister                  map[string]string
+	OldAddressAllocationStrategy *nddov1.AddressAllocationStrategy
+	NewAddressAllocationStrategy *nddov1.AddressAllocationStrategy
+}
+
+// registerState is the effective state of a register
+type registerState struct {
+	namespace    string
+	registerName string
+	organization bool
+	register     map[string]string
+	aas          *nddov1.AddressAllocationStrategy
+}
+
+// watcher delivers the register events of a register to a channel; events
+// are queued such that a slow consumer does not block the informers.
+type watcher struct {
+	namespace    string
+	registerName string
+
+	m      sync.Mutex
+	queue  []RegisterEvent
+	notify chan struct{}
+	ch     chan RegisterEvent
+}
+
+// Watch streams the changes of the effective register and address allocation
+// strategy of the organization or deployment with the register name, until
+// the context is done. Watch requires a registry with an informer cache.
+func (r *registry) Watch(ctx context.Context, namespace, registerName string) (ch <-chan RegisterEvent, err error) {
+	_, span := startSpan(ctx, "Watch",
+		attribute.String(attrNamespace, namespace),
+		attribute.String(attrRegisterName, registerName))
+	defer func() { endSpan(span, err) }()
+
+	if r.cache == nil {
+		return nil, fmt.Errorf("watch register %s requires a registry with an informer cache", registerName)
+	}
+	if registerName == "" {
+		return nil, wrapError(ErrInvalidRegisterName, fmt.Errorf("wrong input in watch register %s", registerName))
+	}
+	if err := r.startWatch(ctx); err != nil {
+		return nil, err
+	}
+
+	w := &watcher{
+		namespace:    namespace,
+		registerName: registerName,
+		notify:       make(chan struct{}, 1),
+		ch:           make(chan RegisterEvent),
+	}
+	r.watchMutex.Lock()
+	r.watchers[w] = struct{}{}
+	r.watchMutex.Unlock()
+
+	go func() {
+		defer func() {
+			r.watchMutex.Lock()
+			delete(r.watchers, w)
+			r.watchMutex.Unlock()
+			close(w.ch)
+		}()
+		for {
+			w.m.Lock()
+			if len(w.queue) == 0 {
+				w.m.Unlock()
+				select {
+				case <-ctx.Done():
+					return
+				case <-w.notify:
+				}
+				continue
+			}
+			e := w.queue[0]
+			w.queue = w.queue[1:]
+			w.m.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case w.ch <- e:
+			}
+		}
+	}()
+	return w.ch, nil
+}
+
+// startWatch adds the event handlers to the organization and deployment
+// informers once; the handlers dispatch the events to the watchers.
+func (r *registry) startWatch(ctx context.Context) error {
+	r.watchMutex.Lock()
+	defer r.watchMutex.Unlock()
+	if r.watchers != nil {
+		return nil
+	}
+
+	handler := toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			r.dispatch(nil, getRegisterState(obj))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			r.dispatch(getRegisterState(oldObj), getRegisterState(newObj))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			r.dispatch(getRegisterState(obj), nil)
+		},
+	}
+	for _, obj := range []client.Object{&orgv1alpha2.Organization{}, &orgv1alpha2.Deployment{}} {
+		informer, err := r.cache.GetInformer(ctx, obj)
+		if err != nil {
+			return err
+		}
+		informer.AddEventHandler(handler)
+	}
+	r.watchers = make(map[*watcher]struct{})
+	return nil
+}
+
+// dispatch queues a register event for the watchers of the register when its
+// effective state changed.
+func (r *registry) dispatch(oldState, newState *registerState) {
+	if oldState == nil && newState == nil {
+		return
+	}
+	var e RegisterEvent
+	if oldState != nil {
+		e.Namespace = oldState.namespace
+		e.RegisterName = oldState.registerName
+		e.OldRegister = oldState.register
+		e.OldAddressAllocationStrategy = oldState.aas
+	}
+	if newState != nil {
+		e.Namespace = newState.namespace
+		e.RegisterName = newState.registerName
+		e.NewRegister = newState.register
+		e.NewAddressAllocationStrategy = newState.aas
+	}
+	if oldState != nil && newState != nil &&
+		reflect.DeepEqual(e.OldRegister, e.NewRegister) &&
+		reflect.DeepEqual(e.OldAddressAllocationStrategy, e.NewAddressAllocationStrategy) {
+		return
+	}
+
+	organization := (oldState != nil && oldState.organization) || (newState != nil && newState.organization)
+
+	r.watchMutex.Lock()
+	defer r.watchMutex.Unlock()
+	for w := range r.watchers {
+		// the register of an organization is watched from the namespaces it
+		// governs
+		namespace := w.namespace
+		if organization {
+			namespace = r.organizationNamespace(w.namespace)
+		}
+		if namespace != e.Namespace || w.registerName != e.RegisterName {
+			continue
+		}
+		w.m.Lock()
+		w.queue = append(w.queue, e)
+		w.m.Unlock()
+		select {
+		case w.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func getRegisterState(obj interface{}) *registerState {
+	switch o := obj.(type) {
+	case *orgv1alpha2.Organization:
+		return &registerState{
+			namespace:    o.GetNamespace(),
+			registerName: o.GetName(),
+			organization: true,
+			register:     o.GetStateRegister(),
+			aas:          o.GetStateAddressAllocationStrategy(),
+		}
+	case *orgv1alpha2.Deployment:
+		return &registerState{
+			namespace:    o.GetNamespace(),
+			registerName: deploymentRegisterName(o),
+			register:     o.GetStateRegister(),
+			aas:          o.GetStateAddressAllocationStrategy(),
+		}
+	}
+	return nil
+}
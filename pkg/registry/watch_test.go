@@ -0,0 +1,184 @@
+/*
+Copyright 2021 NDD.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	orgv1alpha2 "github.com/yndd/nddr-organization/apis/org/v1alpha2"
+	"github.com/yndd/nddr-organization/internal/index/indextest"
+	"github.com/yndd/nddr-organization/pkg/registry"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// watchRegistry returns a registry with a cache whose organization informer
+// delivers the events sent to it.
+func watchRegistry(t *testing.T) (registry.Registry, *indextest.Informer) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := orgv1alpha2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := indextest.NewCache(fake.NewClientBuilder().WithScheme(scheme).Build())
+	informer, err := c.Informer(&orgv1alpha2.Organization{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return registry.New(registry.WithCache(c)), informer
+}
+
+func receive(t *testing.T, ch <-chan registry.RegisterEvent) registry.RegisterEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Fatal("watch channel closed")
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch event not delivered")
+	}
+	return registry.RegisterEvent{}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, informer := watchRegistry(t)
+	defer r.Close()
+
+	ch, err := r.Watch(ctx, "default", "nokia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := organization("default", "nokia", map[string]string{"ipam": "nokia.default"})
+	updated := organization("default", "nokia", map[string]string{"ipam": "nokia.other"})
+	// events of other registers and unchanged registers are not delivered
+	informer.Add(organization("default", "other", map[string]string{"ipam": "other.default"}))
+	informer.Add(organization("tenant", "nokia", map[string]string{"ipam": "nokia.default"}))
+	informer.Add(created)
+	informer.Update(created, created.DeepCopy())
+	informer.Update(created, updated)
+	informer.Delete(updated)
+
+	want := []registry.RegisterEvent{
+		{Namespace: "default", RegisterName: "nokia", NewRegister: created.GetStateRegister()},
+		{Namespace: "default", RegisterName: "nokia", OldRegister: created.GetStateRegister(), NewRegister: updated.GetStateRegister()},
+		{Namespace: "default", RegisterName: "nokia", OldRegister: updated.GetStateRegister()},
+	}
+	for i, w := range want {
+		if diff := cmp.Diff(w, receive(t, ch)); diff != "" {
+			t.Errorf("event %d: -want, +got:\n%s", i, diff)
+		}
+	}
+	select {
+	case ev := <-ch:
+		t.Errorf("Watch(...): unexpected event %+v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchSlowWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r, informer := watchRegistry(t)
+	defer r.Close()
+
+	slow, err := r.Watch(ctx, "default", "nokia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := r.Watch(ctx, "default", "nokia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the informer is not blocked by the watcher that does not read
+	const n = 100
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		old := organization("default", "nokia", map[string]string{"ipam": "nokia.0"})
+		informer.Add(old)
+		for i := 1; i < n; i++ {
+			org := organization("default", "nokia", map[string]string{"ipam": fmt.Sprintf("nokia.%d", i)})
+			informer.Update(old, org)
+			old = org
+		}
+	}()
+	for i := 0; i < n; i++ {
+		if got, want := receive(t, fast).NewRegister["ipam"], fmt.Sprintf("nokia.%d", i); got != want {
+			t.Fatalf("fast watcher event %d: want register %s, got %s", i, want, got)
+		}
+	}
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("informer blocked by the slow watcher")
+	}
+
+	// the slow watcher receives all events once it reads
+	for i := 0; i < n; i++ {
+		if got, want := receive(t, slow).NewRegister["ipam"], fmt.Sprintf("nokia.%d", i); got != want {
+			t.Fatalf("slow watcher event %d: want register %s, got %s", i, want, got)
+		}
+	}
+}
+
+func TestWatchCancel(t *testing.T) {
+	r, informer := watchRegistry(t)
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := r.Watch(ctx, "default", "nokia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := r.Watch(context.Background(), "default", "nokia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if informer.Handlers() != 1 {
+		t.Errorf("Watch(...): want 1 informer handler, got %d", informer.Handlers())
+	}
+
+	// the channel is closed after the cancel, also with a queued event
+	informer.Add(organization("default", "nokia", map[string]string{"ipam": "nokia.default"}))
+	cancel()
+	deadline := time.After(5 * time.Second)
+	for closed := false; !closed; {
+		select {
+		case _, ok := <-ch:
+			closed = !ok
+		case <-deadline:
+			t.Fatal("Watch(...): channel not closed after cancel")
+		}
+	}
+
+	// the other watchers keep receiving events
+	receive(t, other)
+	informer.Delete(organization("default", "nokia", map[string]string{"ipam": "nokia.default"}))
+	if ev := receive(t, other); ev.NewRegister != nil {
+		t.Errorf("Watch(...): want a delete event, got %+v", ev)
+	}
+}